@@ -0,0 +1,58 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/service"
+)
+
+func newBuildCmd(clientset *client.ConfigSet) *cobra.Command {
+	var report string
+	buildCmd := &cobra.Command{
+		Use:   "build [function...]",
+		Short: "Build images of functions defined in yaml manifest",
+		Long: "Build images of all or selected functions defined in yaml manifest without deploying them " +
+			"and write the build report with resulting images. Report may be passed to \"tm deploy --images\" " +
+			"to deploy exactly these images",
+		Example: "tm build -f serverless.yaml --report build-report.json",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			prepareBuild()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			s.Namespace = client.Namespace
+			if report == "-" {
+				// progress is moved to stderr to keep stdout parsable
+				service.Output = cmd.ErrOrStderr()
+			}
+			result, err := s.BuildYAML(yaml, args, concurrency, clientset)
+			if len(result.Functions) != 0 {
+				if err := result.Write(report, cmd.OutOrStdout()); err != nil {
+					clientset.Log.Fatal(err)
+				}
+			}
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+		},
+	}
+	buildCmd.Flags().StringVarP(&yaml, "from", "f", "serverless.yaml", "Build functions defined in yaml")
+	buildCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 3, "Number of concurrent builds")
+	buildCmd.Flags().SetAnnotation("concurrency", profileFlag, []string{"concurrency"})
+	buildCmd.Flags().StringVar(&report, "report", "build-report.json", "Path to write the build report to, \"-\" prints the report to stdout and the build progress to stderr")
+	addBuildFlags(buildCmd.PersistentFlags())
+	return buildCmd
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/cache"
+)
+
+func newCacheCmd(clientset *client.ConfigSet) *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage image build cache",
+	}
+	cacheCmd.AddCommand(cmdPruneCache(clientset))
+	return cacheCmd
+}
+
+func cmdPruneCache(clientset *client.ConfigSet) *cobra.Command {
+	var file string
+	var bc cache.Cache
+	pruneCacheCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove cached image layers and cache volumes",
+		Long: "Remove cached image layers from the registry repository and delete cache volume claims. " +
+			"If neither repository nor volume is set, caches of all functions defined in yaml manifest are pruned",
+		Example: "tm cache prune --repository gcr.io/foo/cache",
+		Run: func(cmd *cobra.Command, args []string) {
+			caches := []cache.Cache{bc}
+			if !bc.Enabled() {
+				s.Namespace = client.Namespace
+				services, err := s.ManifestToServices(file)
+				if err != nil {
+					clientset.Log.Fatal(err)
+				}
+				caches = caches[:0]
+				for _, service := range services {
+					if service.Cache.Enabled() && !cacheListed(caches, service.Cache) {
+						caches = append(caches, service.Cache)
+					}
+				}
+			}
+			if len(caches) == 0 {
+				clientset.Log.Infoln("No build cache configured")
+				return
+			}
+			for _, c := range caches {
+				c.Namespace = client.Namespace
+				removed, err := c.Prune(clientset)
+				if err != nil {
+					clientset.Log.Fatal(err)
+				}
+				if c.Repository != "" {
+					clientset.Log.Infof("Removed %d cached layers from %s", removed, c.Repository)
+				}
+				if c.Volume != "" {
+					clientset.Log.Infof("Cache volume %s removed", c.Volume)
+				}
+			}
+		},
+	}
+	pruneCacheCmd.Flags().StringVarP(&file, "file", "f", "serverless.yaml", "Prune build caches of functions defined in yaml")
+	pruneCacheCmd.Flags().StringVar(&bc.Repository, "repository", "", "Registry repository with cached image layers")
+	pruneCacheCmd.Flags().StringVar(&bc.Volume, "volume", "", "Name of cache volume claim")
+	return pruneCacheCmd
+}
+
+func cacheListed(list []cache.Cache, c cache.Cache) bool {
+	for _, v := range list {
+		if v.Repository == c.Repository && v.Volume == c.Volume {
+			return true
+		}
+	}
+	return false
+}
@@ -15,8 +15,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/triggermesh/tm/pkg/client"
@@ -30,6 +35,9 @@ import (
 	"github.com/triggermesh/tm/pkg/resources/service"
 	"github.com/triggermesh/tm/pkg/resources/task"
 	"github.com/triggermesh/tm/pkg/resources/taskrun"
+	"github.com/triggermesh/tm/pkg/runtimes"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	// Required for configs with gcp auth provider
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -43,9 +51,19 @@ var (
 	yaml            string
 	concurrency     int
 	debug           bool
+	logFormat       string
 	registryHost    string
 	registrySecret  string
 	registrySkipTLS bool
+	timeout         time.Duration
+	// stopTimeout releases --timeout timer
+	stopTimeout context.CancelFunc
+
+	uploadCompression string
+	uploadLimit       string
+	uploadRetries     int
+	buildRetries      int
+	imagesReport      string
 
 	c   channel.Channel
 	t   task.Task
@@ -58,6 +76,7 @@ var (
 	cf  configuration.Configuration
 	gc  credential.GitCreds
 	rc  credential.RegistryCreds
+	cr  credential.Credentials
 )
 
 // tmCmd represents the base command when called without any subcommands
@@ -69,30 +88,80 @@ var tmCmd = &cobra.Command{
 
 // Execute runs main CLI command
 func Execute() {
-	if err := tmCmd.Execute(); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cancelOnInterrupt(cancel)
+	err := tmCmd.ExecuteContext(ctx)
+	if stopTimeout != nil {
+		stopTimeout()
+	}
+	if err != nil {
 		log.Fatalln(err)
 	}
 }
 
+// cancelOnInterrupt cancels root context on SIGINT or SIGTERM
+// so that API requests are aborted, running builds are cancelled and waits are stopped.
+// Second signal terminates the process immediately.
+func cancelOnInterrupt(cancel context.CancelFunc) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		message := "Interrupted, cancelling running operations. Press Ctrl-C again to exit immediately"
+		if clientset.Log != nil {
+			clientset.Log.Warnln(message)
+		} else {
+			fmt.Fprintln(os.Stderr, message)
+		}
+		cancel()
+		<-signals
+		os.Exit(130)
+	}()
+}
+
+// commandContext returns root context limited with --timeout
+func commandContext() context.Context {
+	ctx := tmCmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout > 0 {
+		ctx, stopTimeout = context.WithTimeout(ctx, timeout)
+	}
+	return ctx
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 	tmCmd.PersistentFlags().StringVar(&kubeConf, "config", "", "k8s config file")
 	tmCmd.PersistentFlags().StringVarP(&client.Namespace, "namespace", "n", "", "User namespace")
 	tmCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable debug output")
+	tmCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log messages format: text or json")
 	tmCmd.PersistentFlags().StringVar(&registryHost, "registry-host", "knative.registry.svc.cluster.local", "Docker registry host address")
 	tmCmd.PersistentFlags().StringVar(&registrySecret, "registry-secret", "", "K8s secret name to use as image registry credentials")
 	tmCmd.PersistentFlags().BoolVar(&registrySkipTLS, "registry-skip-tls", false, "Accept untrusted registries certificates")
-	tmCmd.PersistentFlags().StringVarP(&client.Output, "output", "o", "", "Output format")
+	tmCmd.PersistentFlags().StringVarP(&client.Output, "output", "o", "", "Output format: yaml, json, wide, name, jsonpath=..., go-template=... or custom-columns=HEADER:jsonpath,...")
 	tmCmd.PersistentFlags().BoolVar(&client.Wait, "wait", false, "Wait for the operation to complete")
 	tmCmd.PersistentFlags().BoolVar(&client.Dry, "dry", false, "Do not create k8s objects, just print its structure")
+	tmCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Maximum duration of the command, e.g. 30s or 10m, zero means no limit")
+	tmCmd.PersistentFlags().StringVar(&runtimes.CatalogSource, "runtime-catalog", runtimeCatalog(), "Local path or URL of the runtimes catalog index, may be set with TM_RUNTIME_CATALOG variable")
 
 	tmCmd.AddCommand(versionCmd)
 	tmCmd.AddCommand(newDeployCmd(&clientset))
+	tmCmd.AddCommand(newBuildCmd(&clientset))
 	tmCmd.AddCommand(newDeleteCmd(&clientset))
 	tmCmd.AddCommand(newGenerateCmd(&clientset))
 	tmCmd.AddCommand(newPushCmd(&clientset))
 	tmCmd.AddCommand(newSetCmd(&clientset))
 	tmCmd.AddCommand(newGetCmd(&clientset))
+	tmCmd.AddCommand(newDescribeCmd(&clientset))
+	tmCmd.AddCommand(newStatusCmd(&clientset))
+	tmCmd.AddCommand(newCacheCmd(&clientset))
+	tmCmd.AddCommand(newRuntimesCmd(&clientset))
+	tmCmd.AddCommand(newGCCmd(&clientset))
+	tmCmd.AddCommand(newSecretCmd(&clientset))
+	tmCmd.AddCommand(newConfigCmd())
 }
 
 var versionCmd = &cobra.Command{
@@ -103,16 +172,50 @@ var versionCmd = &cobra.Command{
 	},
 }
 
+func runtimeCatalog() string {
+	if catalog, ok := os.LookupEnv("TM_RUNTIME_CATALOG"); ok {
+		return catalog
+	}
+	return runtimes.DefaultCatalog
+}
+
 func initConfig() {
+	cmd, _, err := tmCmd.Find(os.Args[1:])
+	if err != nil {
+		cmd = tmCmd
+	}
+	if err := applyProfile(cmd); err != nil {
+		log.Fatalln(err)
+	}
+	if isConfigCmd(cmd) {
+		return
+	}
 	confPath := client.ConfigPath(kubeConf)
 	if clientset, err = client.NewClient(confPath, tmCmd.OutOrStdout()); err != nil {
 		log.Fatalln(err)
 	}
 	clientset.Printer.Format = client.Output
+	clientset.Context = commandContext()
+	if err := clientset.Log.SetFormat(logFormat); err != nil {
+		log.Fatalln(err)
+	}
 	if debug {
 		clientset.Log.SetDebugLevel()
 	}
 	clientset.Registry.Host = registryHost
 	clientset.Registry.Secret = registrySecret
 	clientset.Registry.SkipTLS = registrySkipTLS
+	clientset.Upload.Compression = uploadCompression
+	clientset.Upload.Retries = uploadRetries
+	s.Decrypt = decryptSecret(&clientset)
+	s.GitAuth = func(url string) (transport.AuthMethod, error) {
+		return credential.GitAuth(&clientset, client.Namespace, url)
+	}
+	if uploadLimit != "" {
+		limit, err := resource.ParseQuantity(uploadLimit)
+		if err != nil {
+			log.Fatalf("Invalid upload limit %q: %s", uploadLimit, err)
+		}
+		clientset.Upload.SizeLimit = limit.Value()
+	}
 }
@@ -0,0 +1,249 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	k8syaml "github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
+)
+
+// profileFlag marks flags which default values are taken from the current profile
+const profileFlag = "tm-profile"
+
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage tm contexts",
+		Long: "Manage named contexts stored in " + client.TMConfigPath() + ". " +
+			"Context keeps kubeconfig context, namespace, registry and output defaults, " +
+			"command line flags override context values",
+	}
+	configCmd.AddCommand(cmdConfigSetContext())
+	configCmd.AddCommand(cmdConfigUseContext())
+	configCmd.AddCommand(cmdConfigGetContexts())
+	configCmd.AddCommand(cmdConfigDeleteContext())
+	configCmd.AddCommand(cmdConfigView())
+	return configCmd
+}
+
+func cmdConfigSetContext() *cobra.Command {
+	var profile client.Profile
+	setContextCmd := &cobra.Command{
+		Use:   "set-context <name>",
+		Short: "Create or update context",
+		Long: "Create or update context. Only passed flags are changed in existing context, " +
+			"namespace, registry and output values are taken from the global flags",
+		Example: "tm config set-context dev --kube-context minikube -n dev --registry-host gcr.io/project --registry-secret gcr\n" +
+			"tm config set-context dev --concurrency 5 -o yaml",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			config, err := client.LoadTMConfig(client.TMConfigPath())
+			if err != nil {
+				log.Fatalln(err)
+			}
+			p, _ := config.Profile(args[0])
+			p.Name = args[0]
+			flags := cmd.Flags()
+			if flags.Changed("kubeconfig") {
+				p.Kubeconfig = profile.Kubeconfig
+			}
+			if flags.Changed("kube-context") {
+				p.Context = profile.Context
+			}
+			if flags.Changed("concurrency") {
+				p.Concurrency = profile.Concurrency
+			}
+			if flags.Changed("namespace") {
+				p.Namespace = client.Namespace
+			}
+			if flags.Changed("registry-host") {
+				p.RegistryHost = registryHost
+			}
+			if flags.Changed("registry-secret") {
+				p.RegistrySecret = registrySecret
+			}
+			if flags.Changed("registry-skip-tls") {
+				p.RegistrySkipTLS = registrySkipTLS
+			}
+			if flags.Changed("output") {
+				p.Output = client.Output
+			}
+			config.SetProfile(p)
+			if config.CurrentProfile == "" {
+				config.CurrentProfile = p.Name
+			}
+			if err := config.Save(client.TMConfigPath()); err != nil {
+				log.Fatalln(err)
+			}
+			fmt.Printf("Context %q saved\n", p.Name)
+		},
+	}
+	setContextCmd.Flags().StringVar(&profile.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
+	setContextCmd.Flags().StringVar(&profile.Context, "kube-context", "", "Kubeconfig context name")
+	setContextCmd.Flags().IntVarP(&profile.Concurrency, "concurrency", "c", 0, "Default number of concurrent deploy, build and delete threads")
+	return setContextCmd
+}
+
+func cmdConfigUseContext() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use-context <name>",
+		Short: "Set current context",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			config, err := client.LoadTMConfig(client.TMConfigPath())
+			if err != nil {
+				log.Fatalln(err)
+			}
+			if _, ok := config.Profile(args[0]); !ok {
+				log.Fatalf("context %q not found\n", args[0])
+			}
+			config.CurrentProfile = args[0]
+			if err := config.Save(client.TMConfigPath()); err != nil {
+				log.Fatalln(err)
+			}
+			fmt.Printf("Switched to context %q\n", args[0])
+		},
+	}
+}
+
+func cmdConfigGetContexts() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get-contexts",
+		Short: "List contexts",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			config, err := client.LoadTMConfig(client.TMConfigPath())
+			if err != nil {
+				log.Fatalln(err)
+			}
+			printer.NewPrinter(cmd.OutOrStdout()).PrintTable(config.GetTable())
+		},
+	}
+}
+
+func cmdConfigDeleteContext() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete-context <name>",
+		Short: "Delete context",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			config, err := client.LoadTMConfig(client.TMConfigPath())
+			if err != nil {
+				log.Fatalln(err)
+			}
+			if err := config.DeleteProfile(args[0]); err != nil {
+				log.Fatalln(err)
+			}
+			if err := config.Save(client.TMConfigPath()); err != nil {
+				log.Fatalln(err)
+			}
+			fmt.Printf("Context %q deleted\n", args[0])
+		},
+	}
+}
+
+func cmdConfigView() *cobra.Command {
+	return &cobra.Command{
+		Use:   "view",
+		Short: "Print tm config",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			config, err := client.LoadTMConfig(client.TMConfigPath())
+			if err != nil {
+				log.Fatalln(err)
+			}
+			var data []byte
+			if client.Output == "json" {
+				data, err = json.MarshalIndent(config, "", "  ")
+				data = append(data, '\n')
+			} else {
+				data, err = k8syaml.Marshal(config)
+			}
+			if err != nil {
+				log.Fatalln(err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s", data)
+		},
+	}
+}
+
+// isConfigCmd returns true for "tm config" commands which do not need cluster access
+func isConfigCmd(cmd *cobra.Command) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if cmd.Parent() == tmCmd && cmd.Name() == "config" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyProfile sets default values of the executed command flags from the
+// current tm context. Flags passed in command line are left untouched
+func applyProfile(cmd *cobra.Command) error {
+	config, err := client.LoadTMConfig(client.TMConfigPath())
+	if err != nil {
+		return err
+	}
+	profile, ok := config.Current()
+	if !ok {
+		return nil
+	}
+	client.KubeContext = profile.Context
+	if kubeConf == "" {
+		kubeConf = profile.Kubeconfig
+	}
+	values := map[string]string{
+		"namespace":       profile.Namespace,
+		"registry-host":   profile.RegistryHost,
+		"registry-secret": profile.RegistrySecret,
+		"output":          profile.Output,
+	}
+	if profile.RegistrySkipTLS {
+		values["registry-skip-tls"] = "true"
+	}
+	if profile.Concurrency != 0 {
+		values["concurrency"] = strconv.Itoa(profile.Concurrency)
+	}
+	var errs []string
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		value := values[f.Name]
+		if f.Changed || value == "" {
+			return
+		}
+		if f.Name == "concurrency" {
+			// same flag name is used for container concurrency in "tm deploy service"
+			if _, ok := f.Annotations[profileFlag]; !ok {
+				return
+			}
+		}
+		if err := f.Value.Set(value); err != nil {
+			errs = append(errs, fmt.Sprintf("context %q %s: %s", profile.Name, f.Name, err))
+		}
+	})
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/service"
 )
 
 // NewDeleteCmd returns cobra Command with set of resource deletion subcommands
@@ -29,7 +30,16 @@ func newDeleteCmd(clientset *client.ConfigSet) *cobra.Command {
 		Short: "Delete knative resource",
 		Run: func(cmd *cobra.Command, args []string) {
 			s.Namespace = client.Namespace
-			if err := s.DeleteYAML(file, args, concurrency, clientset); err != nil {
+			if jsonResults(clientset) {
+				service.Output = cmd.ErrOrStderr()
+			}
+			results, err := s.DeleteYAML(file, args, concurrency, clientset)
+			if jsonResults(clientset) {
+				if err := service.WriteResults(cmd.OutOrStdout(), results); err != nil {
+					log.Fatal(err)
+				}
+			}
+			if err != nil {
 				log.Fatal(err)
 			}
 		},
@@ -37,6 +47,7 @@ func newDeleteCmd(clientset *client.ConfigSet) *cobra.Command {
 
 	deleteCmd.Flags().StringVarP(&file, "file", "f", "serverless.yaml", "Delete functions defined in yaml")
 	deleteCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 3, "Number of concurrent deletion threads")
+	deleteCmd.Flags().SetAnnotation("concurrency", profileFlag, []string{"concurrency"})
 	deleteCmd.AddCommand(cmdDeleteConfiguration(clientset))
 	deleteCmd.AddCommand(cmdDeleteRevision(clientset))
 	deleteCmd.AddCommand(cmdDeleteService(clientset))
@@ -45,6 +56,7 @@ func newDeleteCmd(clientset *client.ConfigSet) *cobra.Command {
 	deleteCmd.AddCommand(cmdDeleteTask(clientset))
 	deleteCmd.AddCommand(cmdDeleteTaskRun(clientset))
 	deleteCmd.AddCommand(cmdDeletePipelineResource(clientset))
+	deleteCmd.AddCommand(cmdDeleteCredentials(clientset))
 
 	return deleteCmd
 }
@@ -184,3 +196,20 @@ func cmdDeletePipelineResource(clientset *client.ConfigSet) *cobra.Command {
 		},
 	}
 }
+
+func cmdDeleteCredentials(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "credentials",
+		Aliases: []string{"credential", "creds"},
+		Short:   "Delete registry or git credentials and unlink them from service accounts",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cr.Name = args[0]
+			cr.Namespace = client.Namespace
+			if err := cr.Delete(clientset); err != nil {
+				log.Fatalln(err)
+			}
+			clientset.Log.Infoln("Credentials deleted")
+		},
+	}
+}
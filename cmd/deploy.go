@@ -15,8 +15,13 @@
 package cmd
 
 import (
+	"os"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
+	"github.com/triggermesh/tm/pkg/resources/service"
 )
 
 func newDeployCmd(clientset *client.ConfigSet) *cobra.Command {
@@ -24,12 +29,32 @@ func newDeployCmd(clientset *client.ConfigSet) *cobra.Command {
 		Use:     "deploy",
 		Aliases: []string{"create"},
 		Short:   "Deploy knative resource",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			prepareBuild()
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			s.Namespace = client.Namespace
+			if imagesReport != "" {
+				report, err := service.ReadBuildReport(imagesReport)
+				if err != nil {
+					clientset.Log.Fatal(err)
+				}
+				s.Images = report.Images()
+			}
 			if clientset.Log.IsDebug() && concurrency > 1 {
 				clientset.Log.Warnf(`You are about to run %d deployments in parallel with verbose logging - the output may be unreadable.`, concurrency)
 			}
-			if err := s.DeployYAML(yaml, args, concurrency, clientset); err != nil {
+			if jsonResults(clientset) {
+				// progress is moved to stderr to keep stdout parsable
+				service.Output = cmd.ErrOrStderr()
+			}
+			results, err := s.DeployYAML(yaml, args, concurrency, clientset)
+			if jsonResults(clientset) {
+				if err := service.WriteResults(cmd.OutOrStdout(), results); err != nil {
+					clientset.Log.Fatal(err)
+				}
+			}
+			if err != nil {
 				clientset.Log.Fatal(err)
 			}
 		},
@@ -37,6 +62,12 @@ func newDeployCmd(clientset *client.ConfigSet) *cobra.Command {
 
 	deployCmd.Flags().StringVarP(&yaml, "from", "f", "serverless.yaml", "Deploy functions defined in yaml")
 	deployCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 3, "Number on concurrent deployment threads")
+	deployCmd.Flags().SetAnnotation("concurrency", profileFlag, []string{"concurrency"})
+	deployCmd.Flags().IntVar(&s.BuildConcurrency, "build-concurrency", 0, "Number of concurrent image builds, defaults to --concurrency value")
+	deployCmd.Flags().IntVar(&s.DeployConcurrency, "deploy-concurrency", 0, "Number of concurrent service deployments, defaults to --concurrency value")
+	deployCmd.Flags().StringVar(&sealedKeyFile, "secret-key", os.Getenv("TM_SECRET_KEY"), "Local private key file to decrypt manifest secrets, cluster key is used if empty")
+	deployCmd.Flags().StringVar(&imagesReport, "images", "", "Build report produced by \"tm build\", functions are deployed with reported images without rebuilding")
+	addBuildFlags(deployCmd.PersistentFlags())
 
 	deployCmd.AddCommand(cmdDeployService(clientset))
 	deployCmd.AddCommand(cmdDeployChannel(clientset))
@@ -46,6 +77,25 @@ func newDeployCmd(clientset *client.ConfigSet) *cobra.Command {
 	return deployCmd
 }
 
+// addBuildFlags registers image build flags shared by deploy and build commands
+func addBuildFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&uploadCompression, "upload-compression", "gzip", "Compression of local sources upload stream: \"gzip\", \"zstd\" or \"none\"")
+	flags.StringVar(&uploadLimit, "upload-limit", "500Mi", "Maximum size of local sources to upload, empty value disables the limit")
+	flags.IntVar(&uploadRetries, "upload-retries", 3, "Number of attempts to restart sources upload on transient stream failures")
+	flags.IntVar(&buildRetries, "build-retries", 0, "Number of attempts to re-create failed build TaskRun, delay between attempts doubles starting from 5s")
+}
+
+// jsonResults returns true if manifest functions results are requested as JSON objects
+func jsonResults(clientset *client.ConfigSet) bool {
+	return clientset.Printer.Format == printer.FormatJSON && !client.Dry
+}
+
+// prepareBuild applies build flags
+func prepareBuild() {
+	s.BuildRetries = buildRetries
+	tr.Retries = buildRetries
+}
+
 func cmdDeployService(clientset *client.ConfigSet) *cobra.Command {
 	deployServiceCmd := &cobra.Command{
 		Use:     "service",
@@ -73,6 +123,9 @@ func cmdDeployService(clientset *client.ConfigSet) *cobra.Command {
 	deployServiceCmd.Flags().StringVar(&s.Revision, "revision", "master", "Git revision (branch, tag, commit SHA or ref)")
 	deployServiceCmd.Flags().StringVar(&s.Runtime, "runtime", "", "Existing task name, local path or URL to task yaml file")
 	deployServiceCmd.Flags().StringVar(&s.BuildTimeout, "build-timeout", "10m", "Service image build timeout")
+	deployServiceCmd.Flags().StringVar(&s.Cache.Repository, "cache-repo", "", "Registry repository to store image build cache layers in")
+	deployServiceCmd.Flags().StringVar(&s.Cache.Volume, "cache-volume", "", "Name of the volume claim to mount into build steps as a cache")
+	deployServiceCmd.Flags().StringVar(&s.WorkspaceSize, "workspace-size", "", "Size of the volume claim to clone git sources into, emptyDir volume is used if not set")
 	deployServiceCmd.Flags().IntVar(&s.Concurrency, "concurrency", 0, "Number of concurrent events per container: 0 - multiple events, 1 - single event, N - particular number of events")
 	deployServiceCmd.Flags().StringSliceVar(&s.BuildArgs, "build-argument", []string{}, "Build arguments")
 	deployServiceCmd.Flags().StringSliceVar(&s.EnvSecrets, "env-secret", []string{}, "Name of k8s secrets to populate pod environment variables")
@@ -80,6 +133,7 @@ func cmdDeployService(clientset *client.ConfigSet) *cobra.Command {
 	deployServiceCmd.Flags().StringSliceVarP(&s.Labels, "label", "l", []string{}, "Service labels")
 	deployServiceCmd.Flags().StringToStringVarP(&s.Annotations, "annotation", "a", map[string]string{}, "Revision template annotations")
 	deployServiceCmd.Flags().StringSliceVarP(&s.Env, "env", "e", []string{}, "Environment variables of the service, eg. `--env foo=bar`")
+	deployServiceCmd.Flags().StringVar(&s.ServiceAccount, "service-account", "", "Service account of the service and its build")
 	return deployServiceCmd
 }
 
@@ -140,7 +194,10 @@ func cmdDeployTaskRun(clientset *client.ConfigSet) *cobra.Command {
 	}
 	deployTaskRunCmd.Flags().StringVarP(&tr.Task.Name, "task", "t", "", "Name of task to run")
 	deployTaskRunCmd.Flags().StringVarP(&tr.Function.Path, "file", "f", "", "Function source")
-	deployTaskRunCmd.Flags().StringVarP(&tr.PipelineResource.Name, "resources", "r", "", "Name of pipelineresource to pass into task")
+	deployTaskRunCmd.Flags().StringVarP(&tr.PipelineResource.Name, "resources", "r", "", "Name of pipelineresource to pass into task, only needed for tasks with git input resources")
+	deployTaskRunCmd.Flags().StringVar(&tr.Function.Revision, "revision", "master", "Git revision (branch, tag, commit SHA or ref)")
+	deployTaskRunCmd.Flags().StringVar(&tr.ServiceAccount, "service-account", "", "Service account of the TaskRun pod")
+	deployTaskRunCmd.Flags().StringVar(&tr.WorkspaceSize, "workspace-size", "", "Size of the volume claim to clone git sources into, emptyDir volume is used if not set")
 	// deployTaskRunCmd.Flags().StringVarP(&tr.RegistrySecret, "secret", "s", "", "Secret name with registry credentials")
 	deployTaskRunCmd.Flags().StringArrayVar(&tr.Params, "args", []string{}, "Image build arguments")
 	return deployTaskRunCmd
@@ -152,6 +209,8 @@ func cmdDeployPipelineResource(clientset *client.ConfigSet) *cobra.Command {
 		Aliases: []string{"pipelineresources"},
 		Args:    cobra.ExactArgs(1),
 		Short:   "Deploy tekton PipelineResource object",
+		Deprecated: "git sources are cloned into TaskRun workspaces, " +
+			"PipelineResources are only needed for tasks with git input resources",
 		Run: func(cmd *cobra.Command, args []string) {
 			plr.Name = args[0]
 			plr.Namespace = client.Namespace
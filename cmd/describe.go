@@ -0,0 +1,64 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
+)
+
+func newDescribeCmd(clientset *client.ConfigSet) *cobra.Command {
+	describeCmd := &cobra.Command{
+		Use:   "describe",
+		Short: "Show details of k8s resources",
+	}
+	describeCmd.AddCommand(cmdDescribeService(clientset))
+	// other resources are described the same way as in "tm get <resource> <name>"
+	describeCmd.AddCommand(cmdListConfigurations(clientset))
+	describeCmd.AddCommand(cmdListRevision(clientset))
+	describeCmd.AddCommand(cmdListRoute(clientset))
+	describeCmd.AddCommand(cmdListChannels(clientset))
+	describeCmd.AddCommand(cmdListTasks(clientset))
+	describeCmd.AddCommand(cmdListTaskRuns(clientset))
+	describeCmd.AddCommand(cmdListPipelineResources(clientset))
+	describeCmd.AddCommand(cmdListCredentials(clientset))
+	return describeCmd
+}
+
+func cmdDescribeService(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "service",
+		Aliases: []string{"services", "svc"},
+		Short:   "Show knative service with its revisions, routes, event sources, build and events",
+		Example: "tm describe service foo",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			s.Namespace = client.Namespace
+			s.Name = args[0]
+			description, err := s.Describe(clientset)
+			if err != nil {
+				clientset.Log.Fatalln(err)
+			}
+			if format := clientset.Printer.Format; format == "" || format == printer.FormatWide {
+				description.Print(clientset.Printer.Output)
+				return
+			}
+			if err := clientset.Printer.PrintObject(printer.Object{K8sObject: description}); err != nil {
+				clientset.Log.Fatalln(err)
+			}
+		},
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/file"
+	garbage "github.com/triggermesh/tm/pkg/resources/gc"
+)
+
+func newGCCmd(clientset *client.ConfigSet) *cobra.Command {
+	var olderThan string
+	gcCmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove stale build objects and temporary files",
+		Long: "Remove Tasks, TaskRuns and PipelineResources created by tm which owners no longer exist, " +
+			"and local temporary files left by builds. Use --dry to list objects without removing them",
+		Example: "tm gc --older-than 7d --dry",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			age, err := garbage.ParseAge(olderThan)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			g := garbage.GC{
+				Namespace: client.Namespace,
+				OlderThan: age,
+			}
+			items, err := g.List(clientset)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			if len(items) == 0 {
+				clientset.Log.Infoln("Nothing to remove")
+				return
+			}
+			clientset.Printer.PrintTable(g.GetTable(items))
+			verb := "Would remove"
+			if !client.Dry {
+				verb = "Removed"
+				items, err = g.Delete(clientset, items)
+				if err != nil {
+					clientset.Log.Errorln(err)
+				}
+			}
+			var objects, paths int
+			var size int64
+			for _, item := range items {
+				if item.Kind == garbage.KindLocal {
+					paths++
+					size += item.Size
+					continue
+				}
+				objects++
+			}
+			clientset.Log.Infof("%s %d objects, %d local paths (%s)", verb, objects, paths, file.HumanBytes(size))
+			if err != nil {
+				clientset.Log.Fatal("Garbage collection finished with errors")
+			}
+		},
+	}
+	gcCmd.Flags().StringVar(&olderThan, "older-than", "7d", "Minimum age of removed objects, e.g. 12h or 7d")
+	return gcCmd
+}
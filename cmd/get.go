@@ -20,22 +20,49 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	messagingapi "knative.dev/eventing/pkg/apis/messaging/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
 var (
-	data string
+	data         string
+	sortBy       string
+	watchObjects bool
 )
 
 // getCmd represents the get command
 var getCmd = &cobra.Command{
 	Use:     "get",
-	Aliases: []string{"list", "describe"},
+	Aliases: []string{"list"},
 	Short:   "Retrieve resources from k8s cluster",
 }
 
 // NewGetCmd returns "Get" cobra CLI command with its subcommands
 func newGetCmd(clientset *client.ConfigSet) *cobra.Command {
+	getCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		// single objects are requested from the current namespace
+		if client.AllNamespaces && len(args) == 0 {
+			client.Namespace = metav1.NamespaceAll
+			clientset.Printer.AllNamespaces = true
+		}
+		clientset.Printer.SortBy = sortBy
+	}
+	getCmd.PersistentFlags().StringVarP(&client.LabelSelector, "selector", "l", "", "Label selector to filter listed objects, e.g. -l app=foo,tier!=db")
+	getCmd.PersistentFlags().StringVar(&client.FieldSelector, "field-selector", "", "Field selector to filter listed objects, e.g. --field-selector metadata.name=foo")
+	getCmd.PersistentFlags().BoolVarP(&client.AllNamespaces, "all-namespaces", "A", false, "List objects across all namespaces")
+	getCmd.PersistentFlags().StringVar(&sortBy, "sort-by", "", "Sort list by column name, e.g. \"age\", or JSONPath expression, e.g. \"{.metadata.name}\"")
+	getCmd.PersistentFlags().Int64Var(&client.ChunkSize, "chunk-size", client.DefaultChunkSize, "Number of objects requested in one page, 0 disables pagination")
+	getCmd.PersistentFlags().BoolVarP(&watchObjects, "watch", "w", false, "Watch for changes after listing objects")
+
 	getCmd.AddCommand(cmdListConfigurations(clientset))
 	getCmd.AddCommand(cmdListRevision(clientset))
 	getCmd.AddCommand(cmdListRoute(clientset))
@@ -44,6 +71,7 @@ func newGetCmd(clientset *client.ConfigSet) *cobra.Command {
 	getCmd.AddCommand(cmdListTasks(clientset))
 	getCmd.AddCommand(cmdListTaskRuns(clientset))
 	getCmd.AddCommand(cmdListPipelineResources(clientset))
+	getCmd.AddCommand(cmdListCredentials(clientset))
 
 	return getCmd
 }
@@ -55,24 +83,21 @@ func cmdListChannels(clientset *client.ConfigSet) *cobra.Command {
 		Short:   "List of knative channel resources",
 		Run: func(cmd *cobra.Command, args []string) {
 			c.Namespace = client.Namespace
-			if len(args) == 0 {
-				list, err := c.List(clientset)
-				if err != nil {
-					clientset.Log.Fatalln(err)
-				}
-				if len(list.Items) == 0 {
-					fmt.Fprintf(cmd.OutOrStdout(), "No channels found\n")
-					return
-				}
-				clientset.Printer.PrintTable(c.GetTable(list))
-				return
-			}
-			c.Name = args[0]
-			channel, err := c.Get(clientset)
-			if err != nil {
-				clientset.Log.Fatalln(err)
-			}
-			clientset.Printer.PrintObject(c.GetObject(channel))
+			listCmd[*messagingapi.InMemoryChannelList, *messagingapi.InMemoryChannel]{
+				notFound: "No channels found",
+				list: func() (*messagingapi.InMemoryChannelList, error) {
+					return c.List(clientset)
+				},
+				table: c.GetTable,
+				watch: func(opts metav1.ListOptions) (watch.Interface, error) {
+					return c.Watch(clientset, opts)
+				},
+				get: func(name string) (*messagingapi.InMemoryChannel, error) {
+					c.Name = name
+					return c.Get(clientset)
+				},
+				object: c.GetObject,
+			}.run(clientset, cmd, args)
 		},
 	}
 }
@@ -85,24 +110,21 @@ func cmdListService(clientset *client.ConfigSet) *cobra.Command {
 		Args:    cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			s.Namespace = client.Namespace
-			if len(args) == 0 {
-				list, err := s.List(clientset)
-				if err != nil {
-					clientset.Log.Fatalln(err)
-				}
-				if len(list.Items) == 0 {
-					fmt.Fprintf(cmd.OutOrStdout(), "No services found\n")
-					return
-				}
-				clientset.Printer.PrintTable(s.GetTable(list))
-				return
-			}
-			s.Name = args[0]
-			service, err := s.Get(clientset)
-			if err != nil {
-				clientset.Log.Fatalln(err)
-			}
-			clientset.Printer.PrintObject(s.GetObject(service))
+			listCmd[*servingv1.ServiceList, *servingv1.Service]{
+				notFound: "No services found",
+				list: func() (*servingv1.ServiceList, error) {
+					return s.List(clientset)
+				},
+				table: s.GetTable,
+				watch: func(opts metav1.ListOptions) (watch.Interface, error) {
+					return s.Watch(clientset, opts)
+				},
+				get: func(name string) (*servingv1.Service, error) {
+					s.Name = name
+					return s.Get(clientset)
+				},
+				object: s.GetObject,
+			}.run(clientset, cmd, args)
 		},
 	}
 }
@@ -114,24 +136,21 @@ func cmdListConfigurations(clientset *client.ConfigSet) *cobra.Command {
 		Short:   "List of configurations",
 		Run: func(cmd *cobra.Command, args []string) {
 			cf.Namespace = client.Namespace
-			if len(args) == 0 {
-				list, err := cf.List(clientset)
-				if err != nil {
-					clientset.Log.Fatalln(err)
-				}
-				if len(list.Items) == 0 {
-					fmt.Fprintf(cmd.OutOrStdout(), "No configurations found\n")
-					return
-				}
-				clientset.Printer.PrintTable(cf.GetTable(list))
-				return
-			}
-			cf.Name = args[0]
-			configuration, err := cf.Get(clientset)
-			if err != nil {
-				clientset.Log.Fatalln(err)
-			}
-			clientset.Printer.PrintObject(cf.GetObject(configuration))
+			listCmd[*servingv1.ConfigurationList, *servingv1.Configuration]{
+				notFound: "No configurations found",
+				list: func() (*servingv1.ConfigurationList, error) {
+					return cf.List(clientset)
+				},
+				table: cf.GetTable,
+				watch: func(opts metav1.ListOptions) (watch.Interface, error) {
+					return cf.Watch(clientset, opts)
+				},
+				get: func(name string) (*servingv1.Configuration, error) {
+					cf.Name = name
+					return cf.Get(clientset)
+				},
+				object: cf.GetObject,
+			}.run(clientset, cmd, args)
 		},
 	}
 }
@@ -143,24 +162,21 @@ func cmdListRevision(clientset *client.ConfigSet) *cobra.Command {
 		Short:   "List of knative revision resources",
 		Run: func(cmd *cobra.Command, args []string) {
 			r.Namespace = client.Namespace
-			if len(args) == 0 {
-				list, err := r.List(clientset)
-				if err != nil {
-					clientset.Log.Fatalln(err)
-				}
-				if len(list.Items) == 0 {
-					fmt.Fprintf(cmd.OutOrStdout(), "No revisions found\n")
-					return
-				}
-				clientset.Printer.PrintTable(r.GetTable(list))
-				return
-			}
-			r.Name = args[0]
-			revision, err := r.Get(clientset)
-			if err != nil {
-				clientset.Log.Fatalln(err)
-			}
-			clientset.Printer.PrintObject(r.GetObject(revision))
+			listCmd[*servingv1.RevisionList, *servingv1.Revision]{
+				notFound: "No revisions found",
+				list: func() (*servingv1.RevisionList, error) {
+					return r.List(clientset)
+				},
+				table: r.GetTable,
+				watch: func(opts metav1.ListOptions) (watch.Interface, error) {
+					return r.Watch(clientset, opts)
+				},
+				get: func(name string) (*servingv1.Revision, error) {
+					r.Name = name
+					return r.Get(clientset)
+				},
+				object: r.GetObject,
+			}.run(clientset, cmd, args)
 		},
 	}
 }
@@ -172,20 +188,20 @@ func cmdListRoute(clientset *client.ConfigSet) *cobra.Command {
 		Short:   "List of knative routes resources",
 		Run: func(cmd *cobra.Command, args []string) {
 			rt.Namespace = client.Namespace
-			if len(args) == 0 {
-				list, err := rt.List(clientset)
-				if err != nil {
-					clientset.Log.Fatalln(err)
-				}
-				clientset.Printer.PrintTable(rt.GetTable(list))
-				return
-			}
-			rt.Name = args[0]
-			route, err := rt.Get(clientset)
-			if err != nil {
-				clientset.Log.Fatalln(err)
-			}
-			clientset.Printer.PrintObject(rt.GetObject(route))
+			listCmd[*servingv1.RouteList, *servingv1.Route]{
+				list: func() (*servingv1.RouteList, error) {
+					return rt.List(clientset)
+				},
+				table: rt.GetTable,
+				watch: func(opts metav1.ListOptions) (watch.Interface, error) {
+					return rt.Watch(clientset, opts)
+				},
+				get: func(name string) (*servingv1.Route, error) {
+					rt.Name = name
+					return rt.Get(clientset)
+				},
+				object: rt.GetObject,
+			}.run(clientset, cmd, args)
 		},
 	}
 }
@@ -197,20 +213,20 @@ func cmdListTasks(clientset *client.ConfigSet) *cobra.Command {
 		Short:   "List of tekton task resources",
 		Run: func(cmd *cobra.Command, args []string) {
 			t.Namespace = client.Namespace
-			if len(args) == 0 {
-				list, err := t.List(clientset)
-				if err != nil {
-					clientset.Log.Fatalln(err)
-				}
-				clientset.Printer.PrintTable(t.GetTable(list))
-				return
-			}
-			t.Name = args[0]
-			task, err := t.Get(clientset)
-			if err != nil {
-				clientset.Log.Fatalln(err)
-			}
-			clientset.Printer.PrintObject(t.GetObject(task))
+			listCmd[*v1beta1.TaskList, *v1beta1.Task]{
+				list: func() (*v1beta1.TaskList, error) {
+					return t.List(clientset)
+				},
+				table: t.GetTable,
+				watch: func(opts metav1.ListOptions) (watch.Interface, error) {
+					return t.Watch(clientset, opts)
+				},
+				get: func(name string) (*v1beta1.Task, error) {
+					t.Name = name
+					return t.Get(clientset)
+				},
+				object: t.GetObject,
+			}.run(clientset, cmd, args)
 		},
 	}
 }
@@ -222,20 +238,20 @@ func cmdListTaskRuns(clientset *client.ConfigSet) *cobra.Command {
 		Short:   "List of tekton TaskRun resources",
 		Run: func(cmd *cobra.Command, args []string) {
 			tr.Namespace = client.Namespace
-			if len(args) == 0 {
-				list, err := tr.List(clientset)
-				if err != nil {
-					clientset.Log.Fatalln(err)
-				}
-				clientset.Printer.PrintTable(tr.GetTable(list))
-				return
-			}
-			tr.Name = args[0]
-			taskrun, err := tr.Get(clientset)
-			if err != nil {
-				clientset.Log.Fatalln(err)
-			}
-			clientset.Printer.PrintObject(tr.GetObject(taskrun))
+			listCmd[*v1beta1.TaskRunList, *v1beta1.TaskRun]{
+				list: func() (*v1beta1.TaskRunList, error) {
+					return tr.List(clientset)
+				},
+				table: tr.GetTable,
+				watch: func(opts metav1.ListOptions) (watch.Interface, error) {
+					return tr.Watch(clientset, opts)
+				},
+				get: func(name string) (*v1beta1.TaskRun, error) {
+					tr.Name = name
+					return tr.Get(clientset)
+				},
+				object: tr.GetObject,
+			}.run(clientset, cmd, args)
 		},
 	}
 }
@@ -247,20 +263,129 @@ func cmdListPipelineResources(clientset *client.ConfigSet) *cobra.Command {
 		Short:   "List of tekton PipelineResources resources",
 		Run: func(cmd *cobra.Command, args []string) {
 			plr.Namespace = client.Namespace
-			if len(args) == 0 {
-				list, err := plr.List(clientset)
-				if err != nil {
-					clientset.Log.Fatalln(err)
-				}
-				clientset.Printer.PrintTable(plr.GetTable(list))
-				return
-			}
-			plr.Name = args[0]
-			pipelineResource, err := plr.Get(clientset)
+			listCmd[*v1alpha1.PipelineResourceList, *v1alpha1.PipelineResource]{
+				list: func() (*v1alpha1.PipelineResourceList, error) {
+					return plr.List(clientset)
+				},
+				table: plr.GetTable,
+				watch: func(opts metav1.ListOptions) (watch.Interface, error) {
+					return plr.Watch(clientset, opts)
+				},
+				get: func(name string) (*v1alpha1.PipelineResource, error) {
+					plr.Name = name
+					return plr.Get(clientset)
+				},
+				object: plr.GetObject,
+			}.run(clientset, cmd, args)
+		},
+	}
+}
+
+func cmdListCredentials(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "credentials",
+		Aliases: []string{"credential", "creds"},
+		Short:   "List of registry and git credentials created by tm",
+		Run: func(cmd *cobra.Command, args []string) {
+			cr.Namespace = client.Namespace
+			accounts, err := cr.ServiceAccounts(clientset)
 			if err != nil {
 				clientset.Log.Fatalln(err)
 			}
-			clientset.Printer.PrintObject(plr.GetObject(pipelineResource))
+			listCmd[*corev1.SecretList, *corev1.Secret]{
+				list: func() (*corev1.SecretList, error) {
+					return cr.List(clientset)
+				},
+				table: func(list *corev1.SecretList) printer.Table {
+					return cr.GetTable(list, accounts)
+				},
+				watch: func(opts metav1.ListOptions) (watch.Interface, error) {
+					return cr.Watch(clientset, opts)
+				},
+			}.run(clientset, cmd, args)
 		},
 	}
 }
+
+// listCmd is the typed functions of the resource listed by "get" subcommands
+type listCmd[L runtime.Object, O any] struct {
+	// notFound is printed instead of the empty list, if set
+	notFound string
+	list     func() (L, error)
+	table    func(L) printer.Table
+	watch    client.WatchFunc
+	// get and object print single object requested by name,
+	// if get is not set the list is filtered by the name
+	get    func(name string) (O, error)
+	object func(O) printer.Object
+}
+
+// run prints the list, the object requested by name or watches the list
+func (l listCmd[L, O]) run(clientset *client.ConfigSet, cmd *cobra.Command, args []string) {
+	if err := l.print(clientset, cmd, args); err != nil {
+		clientset.Log.Fatalln(err)
+	}
+}
+
+func (l listCmd[L, O]) print(clientset *client.ConfigSet, cmd *cobra.Command, args []string) error {
+	if len(args) != 0 && (watchObjects || l.get == nil) {
+		selectName(args[0])
+	}
+	if watchObjects {
+		return watchList(clientset, func() (runtime.Object, error) {
+			return l.list()
+		}, func(list runtime.Object) printer.Table {
+			return l.table(list.(L))
+		}, l.watch)
+	}
+	if len(args) != 0 && l.get != nil {
+		object, err := l.get(args[0])
+		if err != nil {
+			return err
+		}
+		return clientset.Printer.PrintObject(l.object(object))
+	}
+	list, err := l.list()
+	if err != nil {
+		return err
+	}
+	if l.notFound != "" && meta.LenList(list) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), l.notFound)
+		return nil
+	}
+	return clientset.Printer.PrintList(list, l.table(list))
+}
+
+// selectName limits listed and watched objects to the one with the name
+func selectName(name string) {
+	selector := "metadata.name=" + name
+	if client.FieldSelector != "" {
+		selector = client.FieldSelector + "," + selector
+	}
+	client.FieldSelector = selector
+}
+
+// watchList prints objects list and keeps it updated with the changes until
+// the command is interrupted
+func watchList(clientset *client.ConfigSet, list func() (runtime.Object, error), table func(runtime.Object) printer.Table, open client.WatchFunc) error {
+	objects, err := list()
+	if err != nil {
+		return err
+	}
+	watcher := clientset.Printer.NewListWatcher(objects, table)
+	if err := watcher.Print(); err != nil {
+		return err
+	}
+	opts := client.ListOptions()
+	if accessor, err := meta.ListAccessor(objects); err == nil {
+		opts.ResourceVersion = accessor.GetResourceVersion()
+	}
+	err = client.Watch(clientset.Context, opts, open, func(event watch.Event) (bool, error) {
+		return false, watcher.Update(event)
+	})
+	if clientset.Context.Err() != nil {
+		// interrupted or timed out
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/runtimes"
+)
+
+func newRuntimesCmd(clientset *client.ConfigSet) *cobra.Command {
+	runtimesCmd := &cobra.Command{
+		Use:     "runtimes",
+		Aliases: []string{"runtime"},
+		Short:   "Manage function runtimes catalog",
+		Long: "Runtimes are tekton tasks that build function images. Catalog runtimes can be " +
+			"referenced by short name with optional version, e.g. \"runtime: python37@v1.2\"",
+	}
+	runtimesCmd.AddCommand(cmdListRuntimes(clientset))
+	runtimesCmd.AddCommand(cmdDescribeRuntime(clientset))
+	runtimesCmd.AddCommand(cmdInstallRuntime(clientset))
+	return runtimesCmd
+}
+
+func cmdListRuntimes(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List catalog runtimes",
+		Run: func(cmd *cobra.Command, args []string) {
+			catalog, err := runtimes.LoadCatalog(runtimes.CatalogSource)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			clientset.Printer.PrintTable(catalog.GetTable())
+		},
+	}
+}
+
+func cmdDescribeRuntime(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "describe",
+		Short:   "Show runtime versions",
+		Args:    cobra.ExactArgs(1),
+		Example: "tm runtimes describe python37",
+		Run: func(cmd *cobra.Command, args []string) {
+			catalog, err := runtimes.LoadCatalog(runtimes.CatalogSource)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			ref, ok := runtimes.ParseReference(args[0])
+			if !ok {
+				clientset.Log.Fatalf("Invalid runtime name %q", args[0])
+			}
+			table, err := catalog.GetVersionsTable(ref.Name)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			runtime := catalog.Runtimes[ref.Name]
+			fmt.Fprintf(cmd.OutOrStdout(), "Name:        %s\nDescription: %s\nLatest:      %s\n\n", ref.Name, runtime.Description, runtime.LatestVersion())
+			clientset.Printer.PrintTable(table)
+		},
+	}
+}
+
+func cmdInstallRuntime(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:     "install",
+		Short:   "Download runtimes into the local cache",
+		Long:    "Download runtime task manifests into the local cache, verify their checksums and make them available offline",
+		Args:    cobra.MinimumNArgs(1),
+		Example: "tm runtimes install python37@v1.2 go",
+		Run: func(cmd *cobra.Command, args []string) {
+			catalog, err := runtimes.LoadCatalog(runtimes.CatalogSource)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			for _, arg := range args {
+				ref, ok := runtimes.ParseReference(arg)
+				if !ok {
+					clientset.Log.Fatalf("Invalid runtime name %q", arg)
+				}
+				ref, version, err := catalog.Resolve(ref)
+				if err != nil {
+					clientset.Log.Fatal(err)
+				}
+				path, err := runtimes.Install(ref, version)
+				if err != nil {
+					clientset.Log.Fatal(err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Runtime %s installed to %s\n", ref, path)
+			}
+		},
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/sealed"
+)
+
+// sealedKeyFile is the local private key file, cluster key is used if empty
+var sealedKeyFile string
+
+func newSecretCmd(clientset *client.ConfigSet) *cobra.Command {
+	secretCmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Encrypt values to store them in the manifest",
+		Long: "Encrypt and decrypt manifest secrets values. Key pair is stored in the \"" + sealed.KeySecret + "\" " +
+			"secret in the namespace, or in the local file if --key is set",
+	}
+	secretCmd.PersistentFlags().StringVar(&sealedKeyFile, "key", os.Getenv("TM_SECRET_KEY"), "Local private key file, may be set with TM_SECRET_KEY variable")
+	secretCmd.AddCommand(cmdSecretKeygen(clientset))
+	secretCmd.AddCommand(cmdSecretEncrypt(clientset))
+	secretCmd.AddCommand(cmdSecretDecrypt(clientset))
+	return secretCmd
+}
+
+func cmdSecretKeygen(clientset *client.ConfigSet) *cobra.Command {
+	return &cobra.Command{
+		Use:   "keygen",
+		Short: "Create key pair and print its public key",
+		Long:  "Create key pair in the namespace secret or in the local key file. Existing key is never overwritten",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if sealedKeyFile == "" {
+				key, err := sealed.ClusterKey(clientset, client.Namespace, true)
+				if err != nil {
+					clientset.Log.Fatal(err)
+				}
+				fmt.Println(key.PublicKey())
+				return
+			}
+			key, err := sealed.GenerateKey()
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			if err := sealed.WriteKeyFile(sealedKeyFile, key); err != nil {
+				clientset.Log.Fatal(err)
+			}
+			fmt.Println(key.PublicKey())
+		},
+	}
+}
+
+func cmdSecretEncrypt(clientset *client.ConfigSet) *cobra.Command {
+	var inputFile, publicKey string
+	encryptCmd := &cobra.Command{
+		Use:   "encrypt [value]",
+		Short: "Encrypt value for the manifest secrets",
+		Long: "Encrypt value passed as argument, read from file or stdin. " +
+			"Cluster key pair is created on the first use",
+		Example: "tm secret encrypt s3cr3t\n" +
+			"tm secret encrypt -f certs/ca.crt > certs/ca.crt.enc",
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			value, err := secretInput(args, inputFile)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			var key sealed.KeyPair
+			if publicKey != "" {
+				key, err = sealed.ParsePublicKey(publicKey)
+			} else {
+				key, err = sealedKey(clientset, true)
+			}
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			encrypted, err := key.Encrypt(value)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			fmt.Println(encrypted)
+		},
+	}
+	encryptCmd.Flags().StringVarP(&inputFile, "file", "f", "", "Encrypt the file content")
+	encryptCmd.Flags().StringVar(&publicKey, "public-key", "", "Encrypt with the public key printed by \"tm secret keygen\", no cluster access is required")
+	return encryptCmd
+}
+
+func cmdSecretDecrypt(clientset *client.ConfigSet) *cobra.Command {
+	var inputFile string
+	decryptCmd := &cobra.Command{
+		Use:   "decrypt [value]",
+		Short: "Decrypt value encrypted with \"tm secret encrypt\"",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			value, err := secretInput(args, inputFile)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			key, err := sealedKey(clientset, false)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			decrypted, err := key.Decrypt(string(value))
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			os.Stdout.Write(decrypted)
+		},
+	}
+	decryptCmd.Flags().StringVarP(&inputFile, "file", "f", "", "Decrypt the file content")
+	return decryptCmd
+}
+
+// secretInput returns value from the arguments, file or stdin
+func secretInput(args []string, file string) ([]byte, error) {
+	switch {
+	case len(args) == 1:
+		return []byte(args[0]), nil
+	case file != "":
+		return ioutil.ReadFile(file)
+	}
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimSuffix(string(data), "\n")), nil
+}
+
+// sealedKey returns the key pair from the local file or from the namespace secret
+func sealedKey(clientset *client.ConfigSet, create bool) (sealed.KeyPair, error) {
+	if sealedKeyFile != "" {
+		return sealed.LoadKeyFile(sealedKeyFile)
+	}
+	return sealed.ClusterKey(clientset, client.Namespace, create)
+}
+
+// decryptSecret opens manifest secrets values, the key is loaded on the first use
+func decryptSecret(clientset *client.ConfigSet) func(string) ([]byte, error) {
+	var key *sealed.KeyPair
+	return func(value string) ([]byte, error) {
+		if key == nil {
+			k, err := sealedKey(clientset, false)
+			if err != nil {
+				return nil, err
+			}
+			key = &k
+		}
+		return key.Decrypt(value)
+	}
+}
@@ -15,10 +15,12 @@
 package cmd
 
 import (
+	"io/ioutil"
 	"log"
 
 	"github.com/spf13/cobra"
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/credential"
 )
 
 // setCmd represents the set command
@@ -37,7 +39,11 @@ func cmdSetRegistryCreds(clientset *client.ConfigSet) *cobra.Command {
 	setRegistryCredsCmd := &cobra.Command{
 		Use:   "registry-auth",
 		Short: "Create secret with registry credentials",
-		Args:  cobra.ExactArgs(1),
+		Long: "Create secret with registry credentials or add registry to the existing secret. " +
+			"Credentials may be imported from the local docker config, including ones stored by credential helpers",
+		Example: "tm set registry-auth foo --registry gcr.io --username oauth2accesstoken --password $TOKEN\n" +
+			"tm set registry-auth foo --from-docker-config --registry ghcr.io",
+		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			rc.Name = args[0]
 			rc.Namespace = client.Namespace
@@ -54,24 +60,44 @@ func cmdSetRegistryCreds(clientset *client.ConfigSet) *cobra.Command {
 	setRegistryCredsCmd.Flags().StringVar(&rc.Password, "password", "", "Registry password")
 	setRegistryCredsCmd.Flags().BoolVar(&rc.Pull, "pull", false, "Indicates if this token must be used for pull operations only")
 	setRegistryCredsCmd.Flags().BoolVar(&rc.Push, "push", false, "Indicates if this token must be used for push operations only")
+	setRegistryCredsCmd.Flags().StringVar(&rc.DockerConfig, "from-docker-config", "", "Import credentials from docker config file. If --registry is set, only this registry is imported")
+	setRegistryCredsCmd.Flags().Lookup("from-docker-config").NoOptDefVal = credential.DockerConfigPath()
+	setRegistryCredsCmd.Flags().StringVar(&rc.ServiceAccount, "service-account", "default", "Service account to add pull secret to")
 	return setRegistryCredsCmd
 }
 
 func cmdSetGitCreds(clientset *client.ConfigSet) *cobra.Command {
+	var keyFile string
 	setGitCredsCmd := &cobra.Command{
 		Use:   "git-auth",
 		Short: "Create secret with git credentials",
+		Long: "Create secret with HTTPS token or SSH private key for the git host. " +
+			"Credentials are used to clone private repositories both in build TaskRuns and locally. " +
+			"If neither token nor key file is set, SSH key is read from stdin",
+		Example: "tm set git-auth --host github.com --token $GITHUB_TOKEN\n" +
+			"tm set git-auth --host gitlab.com --ssh-key ~/.ssh/id_rsa",
+		Args: cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			gc.Namespace = client.Namespace
+			if keyFile != "" {
+				key, err := ioutil.ReadFile(keyFile)
+				if err != nil {
+					log.Fatalln(err)
+				}
+				gc.Key = string(key)
+			}
 			if err := gc.CreateGitCreds(clientset); err != nil {
 				log.Fatalln(err)
 			}
-			clientset.Log.Infoln("Git credentials created")
+			clientset.Log.Infof("Git credentials for %s created", gc.Host)
 		},
 	}
 
-	// Use one ssh key for all git sources for now
-	// setGitCredsCmd.Flags().StringVar(&g.Host, "host", "", "Git host address")
-	// setGitCredsCmd.Flags().StringVar(&g.Key, "key", "", "SSH private key")
+	setGitCredsCmd.Flags().StringVar(&gc.Host, "host", "", "Git host address, e.g. github.com")
+	setGitCredsCmd.Flags().StringVar(&gc.Token, "token", "", "Personal access token for HTTPS authentication")
+	setGitCredsCmd.Flags().StringVar(&gc.Username, "username", "", "Username to use with the token (default \"git\")")
+	setGitCredsCmd.Flags().StringVar(&keyFile, "ssh-key", "", "Path to SSH private key file")
+	setGitCredsCmd.Flags().StringVar(&gc.ServiceAccount, "service-account", "default", "Service account used by build TaskRuns")
+	setGitCredsCmd.MarkFlagRequired("host")
 	return setGitCredsCmd
 }
@@ -0,0 +1,53 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
+)
+
+func newStatusCmd(clientset *client.ConfigSet) *cobra.Command {
+	var file string
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the state of functions defined in yaml",
+		Long: "Show ready state, URL, latest revision, image, schedules and last build result of every function " +
+			"defined in yaml, and the drift between the manifest and the cluster",
+		Example: "tm status -f serverless.yaml",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			s.Namespace = client.Namespace
+			functions, err := s.ManifestToServices(file)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			status, err := s.Status(functions, clientset)
+			if err != nil {
+				clientset.Log.Fatal(err)
+			}
+			if format := clientset.Printer.Format; format == "" || format == printer.FormatWide {
+				clientset.Printer.PrintTable(s.GetStatusTable(status))
+				return
+			}
+			if err := clientset.Printer.PrintObject(printer.Object{K8sObject: status}); err != nil {
+				clientset.Log.Fatal(err)
+			}
+		},
+	}
+	statusCmd.Flags().StringVarP(&file, "file", "f", "serverless.yaml", "Manifest with functions definitions")
+	return statusCmd
+}
@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"sync"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Capability is an optional API resource which may be not installed in the cluster
+type Capability struct {
+	// Name of the component that provides the resource
+	Name         string
+	GroupVersion string
+	Resource     string
+	// Hint is the suggestion shown when the capability is missing
+	Hint string
+}
+
+// Capabilities used by CLI commands
+var (
+	KnativeServing  = Capability{Name: "Knative Serving", GroupVersion: "serving.knative.dev/v1", Resource: "services", Hint: "install it to deploy services"}
+	KnativeSources  = Capability{Name: "Knative Eventing", GroupVersion: "sources.knative.dev/v1", Resource: "pingsources", Hint: "install it or remove the schedule"}
+	KnativeChannels = Capability{Name: "Knative Eventing", GroupVersion: "messaging.knative.dev/v1", Resource: "inmemorychannels", Hint: "install it to create channels"}
+	KnativeTriggers = Capability{Name: "Knative Eventing", GroupVersion: "eventing.knative.dev/v1", Resource: "triggers", Hint: "install it to route events to services"}
+	TektonPipelines = Capability{Name: "Tekton Pipelines", GroupVersion: "tekton.dev/v1beta1", Resource: "taskruns", Hint: "install it or deploy from an image"}
+	TektonResources = Capability{Name: "Tekton PipelineResources", GroupVersion: "tekton.dev/v1alpha1", Resource: "pipelineresources", Hint: "install Tekton Pipelines version that supports them or use workspaces"}
+	TektonTriggers  = Capability{Name: "Tekton Triggers", GroupVersion: "triggers.tekton.dev/v1alpha1", Resource: "eventlisteners", Hint: "install it to use triggers"}
+	GithubSource    = Capability{Name: "Knative GitHub source", GroupVersion: "sources.knative.dev/v1alpha1", Resource: "githubsources", Hint: "install it to receive GitHub events"}
+	AllCapabilities = []Capability{KnativeServing, KnativeSources, KnativeChannels, KnativeTriggers, TektonPipelines, TektonResources, TektonTriggers, GithubSource}
+)
+
+// discovery caches API resources of the cluster group versions.
+// Discovery requests are sent once per group version on the first use
+type discovery struct {
+	sync.Mutex
+	resources map[string]map[string]bool
+}
+
+func newDiscovery() *discovery {
+	return &discovery{
+		resources: make(map[string]map[string]bool),
+	}
+}
+
+// CacheDiscovery enables discovery results caching for ConfigSet created without constructor
+func (c *ConfigSet) CacheDiscovery() {
+	c.discovery = newDiscovery()
+}
+
+// Has reports whether the cluster serves capability resource
+func (c *ConfigSet) Has(capability Capability) (bool, error) {
+	if c.discovery == nil {
+		// results are not cached for ConfigSet created without constructor
+		resources, err := c.serverResources(capability)
+		return resources[capability.Resource], err
+	}
+	c.discovery.Lock()
+	defer c.discovery.Unlock()
+	resources, cached := c.discovery.resources[capability.GroupVersion]
+	if !cached {
+		var err error
+		if resources, err = c.serverResources(capability); err != nil {
+			return false, err
+		}
+		c.discovery.resources[capability.GroupVersion] = resources
+	}
+	return resources[capability.Resource], nil
+}
+
+// serverResources returns names of the resources in capability group version
+func (c *ConfigSet) serverResources(capability Capability) (map[string]bool, error) {
+	list, err := c.Core.Discovery().ServerResourcesForGroupVersion(capability.GroupVersion)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, fmt.Errorf("cannot check if %s is installed: %s", capability.Name, err)
+	}
+	resources := make(map[string]bool)
+	if list != nil {
+		for _, resource := range list.APIResources {
+			resources[resource.Name] = true
+		}
+	}
+	return resources, nil
+}
+
+// Require returns an error if any of capabilities is not available in the cluster
+func (c *ConfigSet) Require(capabilities ...Capability) error {
+	for _, capability := range capabilities {
+		ok, err := c.Has(capability)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s is not installed in this cluster; %s", capability.Name, capability.Hint)
+		}
+	}
+	return nil
+}
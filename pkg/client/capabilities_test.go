@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+   http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	kubernetesFake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRequire(t *testing.T) {
+	testCases := []struct {
+		name      string
+		installed []client.Capability
+		require   []client.Capability
+		err       string
+	}{
+		{
+			name:      "all installed",
+			installed: client.AllCapabilities,
+			require:   []client.Capability{client.KnativeServing, client.TektonPipelines},
+		}, {
+			name:      "tekton missing",
+			installed: []client.Capability{client.KnativeServing, client.KnativeSources},
+			require:   []client.Capability{client.KnativeServing, client.TektonPipelines},
+			err:       "Tekton Pipelines is not installed in this cluster; install it or deploy from an image",
+		}, {
+			name:      "resource missing in group version",
+			installed: []client.Capability{client.TektonPipelines},
+			require:   []client.Capability{client.TektonResources},
+			err:       "Tekton PipelineResources is not installed in this cluster; install Tekton Pipelines version that supports them or use workspaces",
+		}, {
+			name:      "nothing installed",
+			installed: nil,
+			require:   []client.Capability{client.KnativeServing},
+			err:       "Knative Serving is not installed in this cluster; install it to deploy services",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewClient()
+			c.Core.(*kubernetesFake.Clientset).Resources = fake.APIResources(tc.installed)
+			err := c.Require(tc.require...)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestDiscoveryCache(t *testing.T) {
+	c := fake.NewClient()
+	core := c.Core.(*kubernetesFake.Clientset)
+
+	for i := 0; i < 3; i++ {
+		ok, err := c.Has(client.TektonPipelines)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+	assert.Len(t, core.Actions(), 1)
+
+	// client.ConfigSet created without constructor asks the cluster every time
+	literal := client.ConfigSet{Core: core}
+	_, err := literal.Has(client.TektonPipelines)
+	assert.NoError(t, err)
+	_, err = literal.Has(client.TektonPipelines)
+	assert.NoError(t, err)
+	assert.Len(t, core.Actions(), 3)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package client
 
 import (
+	"context"
 	"io"
 	"io/ioutil"
 	"log"
@@ -42,8 +43,9 @@ import (
 )
 
 const (
-	confPath        = "/.tm/config.json"
-	defaultRegistry = "knative.registry.svc.cluster.local"
+	confPath = "/.tm/config.json"
+	// DefaultRegistry is the in-cluster registry used when none is configured
+	DefaultRegistry = "knative.registry.svc.cluster.local"
 )
 
 // CLI global flags
@@ -67,19 +69,34 @@ type Registry struct {
 	SkipTLS bool
 }
 
+// Upload contains parameters of local sources upload to the build pods
+type Upload struct {
+	// Compression algorithm of the upload stream
+	Compression string
+	// SizeLimit is the maximum sources size in bytes, 0 means unlimited
+	SizeLimit int64
+	// Retries is the number of attempts to restart failed upload stream
+	Retries int
+}
+
 // ConfigSet contains different information that may be needed by underlying functions
 type ConfigSet struct {
-	Core            *kubernetes.Clientset
-	Serving         *servingApi.Clientset
-	Eventing        *eventingApi.Clientset
-	GithubSource    *githubSource.Clientset
-	TektonPipelines *tektonResource.Clientset
-	TektonTasks     *tektonTask.Clientset
-	TektonTriggers  *triggersApi.Clientset
+	Core            kubernetes.Interface
+	Serving         servingApi.Interface
+	Eventing        eventingApi.Interface
+	GithubSource    githubSource.Interface
+	TektonPipelines tektonResource.Interface
+	TektonTasks     tektonTask.Interface
+	TektonTriggers  triggersApi.Interface
 	Registry        *Registry
+	Upload          *Upload
 	Log             *logwrapper.StandardLogger
 	Printer         *printerwrapper.Printer
 	Config          *rest.Config
+	// Context is cancelled when user interrupts the command
+	Context context.Context
+
+	discovery *discovery
 }
 
 type config struct {
@@ -104,8 +121,12 @@ func getNamespace(kubeCfgFile string) string {
 		log.Printf("Can't parse config body: %s\n", err)
 		return namespace
 	}
+	current := c.CurrentContext
+	if KubeContext != "" {
+		current = KubeContext
+	}
 	for _, context := range c.Contexts {
-		if context.Name == c.CurrentContext {
+		if context.Name == current {
 			if context.Context.Namespace != "" {
 				namespace = context.Context.Namespace
 			}
@@ -125,10 +146,7 @@ func getInClusterNamespace() string {
 
 // ConfigPath calculates local path to get tm config from
 func ConfigPath(cfgFile string) string {
-	homeDir := "."
-	if dir := os.Getenv("HOME"); dir != "" {
-		homeDir = dir
-	}
+	homeDir := homeDir()
 	tmHome := filepath.Dir(homeDir + confPath)
 	if _, err := os.Stat(tmHome); os.IsNotExist(err) {
 		if err := os.MkdirAll(tmHome, 0755); err != nil {
@@ -139,8 +157,10 @@ func ConfigPath(cfgFile string) string {
 	kubeconfig := os.Getenv("KUBECONFIG")
 	if len(cfgFile) != 0 {
 		// using config file passed with --config argument
-	} else if _, err := os.Stat(homeDir + confPath); err == nil {
+	} else if IsKubeconfig(homeDir + confPath) {
 		cfgFile = homeDir + confPath
+	} else if _, err := os.Stat(homeDir + kubeconfPath); err == nil {
+		cfgFile = homeDir + kubeconfPath
 	} else if _, err := os.Stat(kubeconfig); err == nil {
 		cfgFile = kubeconfig
 	} else {
@@ -153,7 +173,9 @@ func ConfigPath(cfgFile string) string {
 func NewClient(cfgFile string, output ...io.Writer) (ConfigSet, error) {
 	var c ConfigSet
 
-	config, err := clientcmd.BuildConfigFromFlags("", cfgFile)
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: cfgFile},
+		&clientcmd.ConfigOverrides{CurrentContext: KubeContext}).ClientConfig()
 	if err != nil {
 		log.Printf("%s, falling back to in-cluster configuration\n", err)
 		if config, err = rest.InClusterConfig(); err != nil {
@@ -166,34 +188,30 @@ func NewClient(cfgFile string, output ...io.Writer) (ConfigSet, error) {
 		Namespace = getNamespace(cfgFile)
 	}
 	c.Config = config
+	c.Context = context.Background()
+	c.discovery = newDiscovery()
 	c.Log = logwrapper.NewLogger()
 	if len(output) == 1 {
 		c.Printer = printerwrapper.NewPrinter(output[0])
 	}
 	c.Registry = &Registry{
-		Host: defaultRegistry,
+		Host: DefaultRegistry,
 	}
-
-	if c.Eventing, err = eventingApi.NewForConfig(config); err != nil {
-		return c, err
-	}
-	if c.Serving, err = servingApi.NewForConfig(config); err != nil {
-		return c, err
+	c.Upload = &Upload{
+		Compression: "gzip",
+		Retries:     3,
 	}
+
+	// core clientset is used for capabilities discovery by every command,
+	// clientsets of the optional components are created on the first use
 	if c.Core, err = kubernetes.NewForConfig(config); err != nil {
 		return c, err
 	}
-	if c.TektonPipelines, err = tektonResource.NewForConfig(config); err != nil {
-		return c, err
-	}
-	if c.TektonTasks, err = tektonTask.NewForConfig(config); err != nil {
-		return c, err
-	}
-	if c.TektonTriggers, err = triggersApi.NewForConfig(config); err != nil {
-		return c, err
-	}
-	if c.GithubSource, err = githubSource.NewForConfig(config); err != nil {
-		return c, err
-	}
+	c.Serving = lazyServing{newLazy(config, servingApi.NewForConfig)}
+	c.Eventing = lazyEventing{newLazy(config, eventingApi.NewForConfig)}
+	c.GithubSource = lazyGithubSource{newLazy(config, githubSource.NewForConfig)}
+	c.TektonPipelines = lazyTektonPipelines{newLazy(config, tektonResource.NewForConfig)}
+	c.TektonTasks = lazyTektonTasks{newLazy(config, tektonTask.NewForConfig)}
+	c.TektonTriggers = lazyTektonTriggers{newLazy(config, triggersApi.NewForConfig)}
 	return c, nil
 }
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestUsername(t *testing.T) {
@@ -76,3 +77,36 @@ func TestGetInClusterNamespace(t *testing.T) {
 	namespace := getInClusterNamespace()
 	assert.Equal(t, "default", namespace)
 }
+
+func TestListPages(t *testing.T) {
+	pages := map[string]string{"": "second", "second": "third", "third": ""}
+	LabelSelector = "app=foo"
+	defer func() { LabelSelector = "" }()
+
+	var requests []metav1.ListOptions
+	err := ListPages(ListOptions(), func(opts metav1.ListOptions) (string, error) {
+		requests = append(requests, opts)
+		return pages[opts.Continue], nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, requests, 3)
+	for _, opts := range requests {
+		assert.Equal(t, "app=foo", opts.LabelSelector)
+		assert.Equal(t, int64(DefaultChunkSize), opts.Limit)
+	}
+	assert.Equal(t, "third", requests[2].Continue)
+}
+
+func TestLazyClientsets(t *testing.T) {
+	c, err := NewClient("../../testfiles/cfgfile-test.json")
+	assert.NoError(t, err)
+	assert.NotNil(t, c.Core)
+
+	tasks := c.TektonTasks.(lazyTektonTasks)
+	triggers := c.TektonTriggers.(lazyTektonTriggers)
+	assert.Nil(t, tasks.clientset)
+
+	assert.NotNil(t, c.TektonTasks.TektonV1beta1())
+	assert.NotNil(t, tasks.clientset)
+	assert.Nil(t, triggers.clientset)
+}
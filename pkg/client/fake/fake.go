@@ -0,0 +1,198 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake provides ConfigSet backed by in-memory clientsets for tests
+package fake
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	tektonTaskFake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	tektonResourceFake "github.com/tektoncd/pipeline/pkg/client/resource/clientset/versioned/fake"
+	triggersFake "github.com/tektoncd/triggers/pkg/client/clientset/versioned/fake"
+	"github.com/triggermesh/tm/pkg/client"
+	logwrapper "github.com/triggermesh/tm/pkg/log"
+	printerwrapper "github.com/triggermesh/tm/pkg/printer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	kubernetesFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	githubSourceFake "knative.dev/eventing-github/pkg/client/clientset/versioned/fake"
+	eventingFake "knative.dev/eventing/pkg/client/clientset/versioned/fake"
+	servingFake "knative.dev/serving/pkg/client/clientset/versioned/fake"
+)
+
+// NewClient returns ConfigSet backed by in-memory clientsets which are
+// populated with passed objects. Each object is added to every clientset
+// that knows its kind
+func NewClient(objects ...runtime.Object) client.ConfigSet {
+	coreScheme, coreObjects := fakeObjects(kubernetesFake.AddToScheme, objects)
+	core := kubernetesFake.NewSimpleClientset(coreObjects...)
+	addFakeReactors(core, coreScheme)
+	// fake cluster has all optional components installed
+	core.Resources = APIResources(client.AllCapabilities)
+
+	servingScheme, servingObjects := fakeObjects(servingFake.AddToScheme, objects)
+	serving := servingFake.NewSimpleClientset(servingObjects...)
+	addFakeReactors(serving, servingScheme)
+
+	eventingScheme, eventingObjects := fakeObjects(eventingFake.AddToScheme, objects)
+	eventing := eventingFake.NewSimpleClientset(eventingObjects...)
+	addFakeReactors(eventing, eventingScheme)
+
+	githubScheme, githubObjects := fakeObjects(githubSourceFake.AddToScheme, objects)
+	github := githubSourceFake.NewSimpleClientset(githubObjects...)
+	addFakeReactors(github, githubScheme)
+
+	resourceScheme, resourceObjects := fakeObjects(tektonResourceFake.AddToScheme, objects)
+	resources := tektonResourceFake.NewSimpleClientset(resourceObjects...)
+	addFakeReactors(resources, resourceScheme)
+
+	taskScheme, taskObjects := fakeObjects(tektonTaskFake.AddToScheme, objects)
+	tasks := tektonTaskFake.NewSimpleClientset(taskObjects...)
+	addFakeReactors(tasks, taskScheme)
+
+	triggersScheme, triggersObjects := fakeObjects(triggersFake.AddToScheme, objects)
+	triggers := triggersFake.NewSimpleClientset(triggersObjects...)
+	addFakeReactors(triggers, triggersScheme)
+
+	c := client.ConfigSet{
+		Core:            core,
+		Serving:         serving,
+		Eventing:        eventing,
+		GithubSource:    github,
+		TektonPipelines: resources,
+		TektonTasks:     tasks,
+		TektonTriggers:  triggers,
+		Registry: &client.Registry{
+			Host: client.DefaultRegistry,
+		},
+		Upload: &client.Upload{
+			Compression: "gzip",
+			Retries:     3,
+		},
+		Log:     logwrapper.NewLogger(),
+		Printer: printerwrapper.NewPrinter(ioutil.Discard),
+		Config:  &rest.Config{},
+		Context: context.Background(),
+	}
+	c.CacheDiscovery()
+	return c
+}
+
+// APIResources returns discovery lists with all capabilities resources
+func APIResources(capabilities []client.Capability) []*metav1.APIResourceList {
+	var lists []*metav1.APIResourceList
+	index := make(map[string]*metav1.APIResourceList)
+	for _, capability := range capabilities {
+		list, ok := index[capability.GroupVersion]
+		if !ok {
+			list = &metav1.APIResourceList{GroupVersion: capability.GroupVersion}
+			index[capability.GroupVersion] = list
+			lists = append(lists, list)
+		}
+		list.APIResources = append(list.APIResources, metav1.APIResource{Name: capability.Resource, Namespaced: true})
+	}
+	return lists
+}
+
+// fakeObjects returns fake clientset scheme and objects which kinds it knows
+func fakeObjects(addToScheme func(*runtime.Scheme) error, objects []runtime.Object) (*runtime.Scheme, []runtime.Object) {
+	scheme := runtime.NewScheme()
+	if err := addToScheme(scheme); err != nil {
+		panic(err)
+	}
+	var known []runtime.Object
+	for _, object := range objects {
+		if _, _, err := scheme.ObjectKinds(object); err == nil {
+			known = append(known, object)
+		}
+	}
+	return scheme, known
+}
+
+// addFakeReactors adds API server behavior missing in fake clientsets:
+// metadata defaulting on create and collection deletion
+func addFakeReactors(c k8stesting.FakeClient, scheme *runtime.Scheme) {
+	c.PrependReactor("create", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		object, err := meta.Accessor(action.(k8stesting.CreateAction).GetObject())
+		if err != nil {
+			return false, nil, nil
+		}
+		if object.GetName() == "" && object.GetGenerateName() != "" {
+			object.SetName(object.GetGenerateName() + utilrand.String(5))
+		}
+		if object.GetUID() == "" {
+			object.SetUID(types.UID(uuid.NewUUID()))
+		}
+		if object.GetCreationTimestamp().Time.IsZero() {
+			object.SetCreationTimestamp(metav1.Time{Time: time.Now()})
+		}
+		return false, nil, nil
+	})
+	c.PrependReactor("delete-collection", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		gvr := action.GetResource()
+		gvk, err := resourceKind(scheme, gvr)
+		if err != nil {
+			return true, nil, err
+		}
+		list, err := c.Tracker().List(gvr, gvk, action.GetNamespace())
+		if err != nil {
+			return true, nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return true, nil, err
+		}
+		selector := action.(k8stesting.DeleteCollectionAction).GetListRestrictions().Labels
+		for _, item := range items {
+			object, err := meta.Accessor(item)
+			if err != nil {
+				return true, nil, err
+			}
+			if selector != nil && !selector.Matches(labels.Set(object.GetLabels())) {
+				continue
+			}
+			if err := c.Tracker().Delete(gvr, object.GetNamespace(), object.GetName()); err != nil {
+				return true, nil, err
+			}
+		}
+		return true, nil, nil
+	})
+}
+
+// resourceKind finds kind of the resource registered in the scheme
+func resourceKind(scheme *runtime.Scheme, gvr schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	for gvk := range scheme.AllKnownTypes() {
+		if gvk.GroupVersion() != gvr.GroupVersion() {
+			continue
+		}
+		if plural, _ := meta.UnsafeGuessKindToResource(gvk); plural == gvr {
+			return gvk, nil
+		}
+	}
+	return schema.GroupVersionKind{}, fmt.Errorf("unknown resource %s", gvr)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestNewClient(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns", Labels: map[string]string{"app": "foo"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "ns"}},
+		&servingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns"}},
+	)
+
+	_, err := c.Serving.ServingV1().Services("ns").Get(ctx, "foo", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	cm, err := c.Core.CoreV1().ConfigMaps("ns").Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{GenerateName: "gen-", Namespace: "ns"}}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, cm.Name, "gen-")
+	assert.NotEmpty(t, cm.UID)
+
+	err = c.Core.CoreV1().ConfigMaps("ns").DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: "app=foo"})
+	assert.NoError(t, err)
+	list, err := c.Core.CoreV1().ConfigMaps("ns").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, list.Items, 2)
+}
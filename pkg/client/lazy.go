@@ -0,0 +1,141 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"log"
+	"sync"
+
+	tektonTask "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	tektonTaskV1alpha1 "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/typed/pipeline/v1alpha1"
+	tektonTaskV1beta1 "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/typed/pipeline/v1beta1"
+	tektonResource "github.com/tektoncd/pipeline/pkg/client/resource/clientset/versioned"
+	tektonResourceV1alpha1 "github.com/tektoncd/pipeline/pkg/client/resource/clientset/versioned/typed/resource/v1alpha1"
+	triggersApi "github.com/tektoncd/triggers/pkg/client/clientset/versioned"
+	triggersV1alpha1 "github.com/tektoncd/triggers/pkg/client/clientset/versioned/typed/triggers/v1alpha1"
+	k8sdiscovery "k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	githubSource "knative.dev/eventing-github/pkg/client/clientset/versioned"
+	githubBindingsV1alpha1 "knative.dev/eventing-github/pkg/client/clientset/versioned/typed/bindings/v1alpha1"
+	githubSourcesV1alpha1 "knative.dev/eventing-github/pkg/client/clientset/versioned/typed/sources/v1alpha1"
+	eventingApi "knative.dev/eventing/pkg/client/clientset/versioned"
+	eventingV1 "knative.dev/eventing/pkg/client/clientset/versioned/typed/eventing/v1"
+	eventingV1beta1 "knative.dev/eventing/pkg/client/clientset/versioned/typed/eventing/v1beta1"
+	flowsV1 "knative.dev/eventing/pkg/client/clientset/versioned/typed/flows/v1"
+	messagingV1 "knative.dev/eventing/pkg/client/clientset/versioned/typed/messaging/v1"
+	sourcesV1 "knative.dev/eventing/pkg/client/clientset/versioned/typed/sources/v1"
+	sourcesV1beta2 "knative.dev/eventing/pkg/client/clientset/versioned/typed/sources/v1beta2"
+	servingApi "knative.dev/serving/pkg/client/clientset/versioned"
+	autoscalingV1alpha1 "knative.dev/serving/pkg/client/clientset/versioned/typed/autoscaling/v1alpha1"
+	servingV1 "knative.dev/serving/pkg/client/clientset/versioned/typed/serving/v1"
+	servingV1alpha1 "knative.dev/serving/pkg/client/clientset/versioned/typed/serving/v1alpha1"
+	servingV1beta1 "knative.dev/serving/pkg/client/clientset/versioned/typed/serving/v1beta1"
+)
+
+// lazy creates the clientset on the first use, so that commands
+// do not pay for clients of the components they do not work with
+type lazy[T any] struct {
+	once      sync.Once
+	config    *rest.Config
+	newClient func(*rest.Config) (T, error)
+	clientset T
+}
+
+func newLazy[T any](config *rest.Config, newClient func(*rest.Config) (T, error)) *lazy[T] {
+	return &lazy[T]{
+		config:    config,
+		newClient: newClient,
+	}
+}
+
+func (l *lazy[T]) get() T {
+	l.once.Do(func() {
+		var err error
+		// config is validated by the core clientset created in advance,
+		// so the error here means that the client generator is broken
+		if l.clientset, err = l.newClient(l.config); err != nil {
+			log.Fatalf("Can't create clientset: %s", err)
+		}
+	})
+	return l.clientset
+}
+
+type lazyServing struct{ *lazy[*servingApi.Clientset] }
+
+func (l lazyServing) Discovery() k8sdiscovery.DiscoveryInterface { return l.get().Discovery() }
+func (l lazyServing) AutoscalingV1alpha1() autoscalingV1alpha1.AutoscalingV1alpha1Interface {
+	return l.get().AutoscalingV1alpha1()
+}
+func (l lazyServing) ServingV1() servingV1.ServingV1Interface { return l.get().ServingV1() }
+func (l lazyServing) ServingV1beta1() servingV1beta1.ServingV1beta1Interface {
+	return l.get().ServingV1beta1()
+}
+func (l lazyServing) ServingV1alpha1() servingV1alpha1.ServingV1alpha1Interface {
+	return l.get().ServingV1alpha1()
+}
+
+type lazyEventing struct{ *lazy[*eventingApi.Clientset] }
+
+func (l lazyEventing) Discovery() k8sdiscovery.DiscoveryInterface { return l.get().Discovery() }
+func (l lazyEventing) EventingV1beta1() eventingV1beta1.EventingV1beta1Interface {
+	return l.get().EventingV1beta1()
+}
+func (l lazyEventing) EventingV1() eventingV1.EventingV1Interface { return l.get().EventingV1() }
+func (l lazyEventing) FlowsV1() flowsV1.FlowsV1Interface          { return l.get().FlowsV1() }
+func (l lazyEventing) MessagingV1() messagingV1.MessagingV1Interface {
+	return l.get().MessagingV1()
+}
+func (l lazyEventing) SourcesV1beta2() sourcesV1beta2.SourcesV1beta2Interface {
+	return l.get().SourcesV1beta2()
+}
+func (l lazyEventing) SourcesV1() sourcesV1.SourcesV1Interface { return l.get().SourcesV1() }
+
+type lazyGithubSource struct{ *lazy[*githubSource.Clientset] }
+
+func (l lazyGithubSource) Discovery() k8sdiscovery.DiscoveryInterface { return l.get().Discovery() }
+func (l lazyGithubSource) BindingsV1alpha1() githubBindingsV1alpha1.BindingsV1alpha1Interface {
+	return l.get().BindingsV1alpha1()
+}
+func (l lazyGithubSource) SourcesV1alpha1() githubSourcesV1alpha1.SourcesV1alpha1Interface {
+	return l.get().SourcesV1alpha1()
+}
+
+type lazyTektonPipelines struct {
+	*lazy[*tektonResource.Clientset]
+}
+
+func (l lazyTektonPipelines) Discovery() k8sdiscovery.DiscoveryInterface { return l.get().Discovery() }
+func (l lazyTektonPipelines) TektonV1alpha1() tektonResourceV1alpha1.TektonV1alpha1Interface {
+	return l.get().TektonV1alpha1()
+}
+
+type lazyTektonTasks struct{ *lazy[*tektonTask.Clientset] }
+
+func (l lazyTektonTasks) Discovery() k8sdiscovery.DiscoveryInterface { return l.get().Discovery() }
+func (l lazyTektonTasks) TektonV1alpha1() tektonTaskV1alpha1.TektonV1alpha1Interface {
+	return l.get().TektonV1alpha1()
+}
+func (l lazyTektonTasks) TektonV1beta1() tektonTaskV1beta1.TektonV1beta1Interface {
+	return l.get().TektonV1beta1()
+}
+
+type lazyTektonTriggers struct{ *lazy[*triggersApi.Clientset] }
+
+func (l lazyTektonTriggers) Discovery() k8sdiscovery.DiscoveryInterface { return l.get().Discovery() }
+func (l lazyTektonTriggers) TriggersV1alpha1() triggersV1alpha1.TriggersV1alpha1Interface {
+	return l.get().TriggersV1alpha1()
+}
@@ -0,0 +1,60 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultChunkSize is the default number of objects requested in one list page
+const DefaultChunkSize = 500
+
+// "tm get" list filters
+var (
+	// LabelSelector passed with "-l" argument filters listed objects by labels
+	LabelSelector string
+	// FieldSelector filters listed objects by fields, e.g. "metadata.name=foo"
+	FieldSelector string
+	// AllNamespaces lists objects across all namespaces
+	AllNamespaces bool
+	// ChunkSize is the number of objects requested in one list page, 0 disables pagination
+	ChunkSize int64 = DefaultChunkSize
+)
+
+// ListOptions returns list request options with "tm get" filters applied
+func ListOptions() metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector: LabelSelector,
+		FieldSelector: FieldSelector,
+		Limit:         ChunkSize,
+	}
+}
+
+// ListPages requests list in ChunkSize pages. Page function is called with
+// options of every request and returns continue token of the received page
+func ListPages(opts metav1.ListOptions, page func(metav1.ListOptions) (string, error)) error {
+	for {
+		next, err := page(opts)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		opts.Continue = next
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	printerwrapper "github.com/triggermesh/tm/pkg/printer"
+)
+
+const kubeconfPath = "/.tm/kubeconfig.json"
+
+// KubeContext is the kubeconfig context to use instead of the current one
+var KubeContext string
+
+// Profile is a named set of CLI defaults stored in tm config
+type Profile struct {
+	Name            string `json:"name"`
+	Kubeconfig      string `json:"kubeconfig,omitempty"`
+	Context         string `json:"context,omitempty"`
+	Namespace       string `json:"namespace,omitempty"`
+	RegistryHost    string `json:"registry-host,omitempty"`
+	RegistrySecret  string `json:"registry-secret,omitempty"`
+	RegistrySkipTLS bool   `json:"registry-skip-tls,omitempty"`
+	Concurrency     int    `json:"concurrency,omitempty"`
+	Output          string `json:"output,omitempty"`
+}
+
+// TMConfig is the content of tm config file
+type TMConfig struct {
+	CurrentProfile string    `json:"current-profile,omitempty"`
+	Profiles       []Profile `json:"profiles"`
+}
+
+// kubeconfig fields used to tell legacy configs apart from profiles
+type kubeconfigProbe struct {
+	Clusters []json.RawMessage `json:"clusters"`
+	Contexts []json.RawMessage `json:"contexts"`
+}
+
+func homeDir() string {
+	if dir := os.Getenv("HOME"); dir != "" {
+		return dir
+	}
+	return "."
+}
+
+// TMConfigPath returns path to the tm config file
+func TMConfigPath() string {
+	return homeDir() + confPath
+}
+
+// IsKubeconfig returns true if file is a kubeconfig rather than tm profiles
+func IsKubeconfig(path string) bool {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var probe kubeconfigProbe
+	if err := json.Unmarshal(data, &probe); err != nil {
+		// kubeconfig may be written in yaml
+		return true
+	}
+	return probe.Clusters != nil || probe.Contexts != nil
+}
+
+// LoadTMConfig reads profiles from path. Missing file and legacy
+// kubeconfig stored in tm config path result in empty config
+func LoadTMConfig(path string) (*TMConfig, error) {
+	c := &TMConfig{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if IsKubeconfig(path) {
+		return c, nil
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %s", path, err)
+	}
+	return c, nil
+}
+
+// Save writes profiles to path. Legacy kubeconfig stored in the same
+// path is moved to ~/.tm/kubeconfig.json to keep it in use
+func (c *TMConfig) Save(path string) error {
+	if IsKubeconfig(path) {
+		if err := os.Rename(path, filepath.Join(filepath.Dir(path), filepath.Base(kubeconfPath))); err != nil {
+			return fmt.Errorf("cannot move kubeconfig: %s", err)
+		}
+	}
+	sort.Slice(c.Profiles, func(i, j int) bool {
+		return c.Profiles[i].Name < c.Profiles[j].Name
+	})
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// Profile returns profile by name
+func (c *TMConfig) Profile(name string) (Profile, bool) {
+	for _, p := range c.Profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// Current returns the profile selected with "tm config use-context"
+func (c *TMConfig) Current() (Profile, bool) {
+	if c.CurrentProfile == "" {
+		return Profile{}, false
+	}
+	return c.Profile(c.CurrentProfile)
+}
+
+// SetProfile adds new profile or replaces existing one with the same name
+func (c *TMConfig) SetProfile(profile Profile) {
+	for i, p := range c.Profiles {
+		if p.Name == profile.Name {
+			c.Profiles[i] = profile
+			return
+		}
+	}
+	c.Profiles = append(c.Profiles, profile)
+}
+
+// DeleteProfile removes profile by name
+func (c *TMConfig) DeleteProfile(name string) error {
+	for i, p := range c.Profiles {
+		if p.Name == name {
+			c.Profiles = append(c.Profiles[:i], c.Profiles[i+1:]...)
+			if c.CurrentProfile == name {
+				c.CurrentProfile = ""
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("context %q not found", name)
+}
+
+// GetTable converts profiles list into printable table
+func (c *TMConfig) GetTable() printerwrapper.Table {
+	table := printerwrapper.Table{
+		Headers: []string{"Current", "Name", "Context", "Namespace", "Registry", "Registry Secret", "Concurrency", "Output"},
+		Rows:    make([][]string, 0, len(c.Profiles)),
+	}
+	for _, p := range c.Profiles {
+		current := ""
+		if p.Name == c.CurrentProfile {
+			current = "*"
+		}
+		concurrency := ""
+		if p.Concurrency != 0 {
+			concurrency = strconv.Itoa(p.Concurrency)
+		}
+		table.Rows = append(table.Rows, []string{current, p.Name, p.Context, p.Namespace, p.RegistryHost, p.RegistrySecret, concurrency, p.Output})
+	}
+	return table
+}
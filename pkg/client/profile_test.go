@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+   http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTMConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tm-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+
+	kubeconfig, err := ioutil.ReadFile("../../testfiles/cfgfile-test.json")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(path, kubeconfig, 0600))
+	assert.True(t, IsKubeconfig(path))
+
+	c, err := LoadTMConfig(path)
+	assert.NoError(t, err)
+	assert.Empty(t, c.Profiles)
+
+	c.SetProfile(Profile{Name: "prod", Namespace: "prod"})
+	c.SetProfile(Profile{Name: "dev", RegistryHost: "gcr.io/dev", Concurrency: 5})
+	c.SetProfile(Profile{Name: "prod", Namespace: "production"})
+	c.CurrentProfile = "prod"
+	assert.NoError(t, c.Save(path))
+
+	moved, err := ioutil.ReadFile(filepath.Join(dir, "kubeconfig.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, kubeconfig, moved)
+	assert.False(t, IsKubeconfig(path))
+
+	c, err = LoadTMConfig(path)
+	assert.NoError(t, err)
+	assert.Len(t, c.Profiles, 2)
+	current, ok := c.Current()
+	assert.True(t, ok)
+	assert.Equal(t, "production", current.Namespace)
+	assert.Equal(t, [][]string{
+		{"", "dev", "", "", "gcr.io/dev", "", "5", ""},
+		{"*", "prod", "", "production", "", "", "", ""},
+	}, [][]string(c.GetTable().Rows))
+
+	assert.NoError(t, c.DeleteProfile("prod"))
+	assert.Error(t, c.DeleteProfile("prod"))
+	_, ok = c.Current()
+	assert.False(t, ok)
+
+	c, err = LoadTMConfig(filepath.Join(dir, "missing.json"))
+	assert.NoError(t, err)
+	assert.Empty(t, c.Profiles)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// RewatchDelay is the pause before closed watch is re-opened
+var RewatchDelay = time.Second
+
+// WatchFunc opens new watch with provided options
+type WatchFunc func(metav1.ListOptions) (watch.Interface, error)
+
+// Watch passes events received from the watch to handle function until it
+// returns true, error or the context is done. API server closes watches
+// periodically, closed watches are re-opened from the last seen resource version
+func Watch(ctx context.Context, opts metav1.ListOptions, open WatchFunc, handle func(watch.Event) (bool, error)) error {
+	// pagination does not apply to watches
+	opts.Limit, opts.Continue = 0, ""
+	w, err := openWatch(open, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		w.Stop()
+	}()
+
+	for {
+		var event watch.Event
+		var ok bool
+		select {
+		case event, ok = <-w.ResultChan():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if event.Type == watch.Error {
+			err := apierrors.FromObject(event.Object)
+			if !apierrors.IsResourceExpired(err) && !apierrors.IsGone(err) {
+				return err
+			}
+			// resource version is too old, start from the current state
+			opts.ResourceVersion = ""
+			ok = false
+		}
+		if !ok || event.Object == nil {
+			w.Stop()
+			select {
+			case <-time.After(RewatchDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if w, err = openWatch(open, opts); err != nil {
+				return err
+			}
+			continue
+		}
+		if accessor, err := meta.Accessor(event.Object); err == nil {
+			opts.ResourceVersion = accessor.GetResourceVersion()
+		}
+		if event.Type == watch.Bookmark {
+			continue
+		}
+		if done, err := handle(event); done || err != nil {
+			return err
+		}
+	}
+}
+
+func openWatch(open WatchFunc, opts metav1.ListOptions) (watch.Interface, error) {
+	w, err := open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("can't get watch interface: %s", err)
+	}
+	if w == nil {
+		return nil, errors.New("can't get watch interface")
+	}
+	return w, nil
+}
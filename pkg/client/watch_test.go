@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2020 TriggerMesh Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+   http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func TestWatch(t *testing.T) {
+	RewatchDelay = time.Millisecond
+	defer func() { RewatchDelay = time.Second }()
+
+	pod := func(name, version string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: version}}
+	}
+	expired := &metav1.Status{Status: metav1.StatusFailure, Code: 410, Reason: metav1.StatusReasonExpired}
+
+	var versions []string
+	open := func(opts metav1.ListOptions) (watch.Interface, error) {
+		versions = append(versions, opts.ResourceVersion)
+		w := watch.NewFakeWithChanSize(2, false)
+		switch len(versions) {
+		case 1:
+			// API server closed the watch
+			w.Add(pod("foo", "2"))
+			w.Stop()
+		case 2:
+			w.Error(expired)
+		default:
+			w.Modify(pod("bar", "5"))
+		}
+		return w, nil
+	}
+
+	var events []string
+	err := Watch(context.Background(), metav1.ListOptions{ResourceVersion: "1", Limit: 10}, open, func(event watch.Event) (bool, error) {
+		events = append(events, string(event.Type)+" "+event.Object.(*corev1.Pod).Name)
+		return event.Type == watch.Modified, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ADDED foo", "MODIFIED bar"}, events)
+	assert.Equal(t, []string{"1", "2", ""}, versions)
+}
+
+func TestWatchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Watch(ctx, metav1.ListOptions{}, func(metav1.ListOptions) (watch.Interface, error) {
+		return watch.NewFake(), nil
+	}, func(watch.Event) (bool, error) {
+		return true, nil
+	})
+	assert.Equal(t, context.Canceled, err)
+}
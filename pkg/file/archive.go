@@ -0,0 +1,307 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/klauspost/compress/zstd"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/gitignore"
+)
+
+// Supported upload compression algorithms
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+	CompressionNone = "none"
+)
+
+// gitignoreFiles contain gitignore patterns of the paths excluded from upload,
+// they are read from every directory and applied to its content
+var gitignoreFiles = []string{".tmignore", ".gitignore"}
+
+// dockerignoreFile is read from the sources root only and matched with docker rules
+const dockerignoreFile = ".dockerignore"
+
+type archiveFile struct {
+	path   string
+	name   string
+	info   os.FileInfo
+	target string
+}
+
+// archive is a list of local files to be packed into tar stream
+type archive struct {
+	files []archiveFile
+	size  int64
+}
+
+// newArchive walks through the source path, skips ignored files
+// and returns the list of files to upload with their total size
+func newArchive(source string) (*archive, error) {
+	source = filepath.Clean(source)
+	root := source
+	if !IsDir(source) {
+		root = filepath.Dir(source)
+	}
+	rules, err := newIgnoreRules(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var a archive
+	base := filepath.Dir(source)
+	err = filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." {
+			ignored, walkIn, err := rules.match(rel, info.IsDir())
+			if err != nil {
+				return err
+			}
+			if ignored && !walkIn {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() {
+				if err := rules.load(root, rel); err != nil {
+					return err
+				}
+			}
+			if ignored {
+				return nil
+			}
+		}
+		name, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		file := archiveFile{
+			path: path,
+			name: filepath.ToSlash(name),
+			info: info,
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if file.target, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		if info.Mode().IsRegular() {
+			a.size += info.Size()
+		}
+		a.files = append(a.files, file)
+		return nil
+	})
+	return &a, err
+}
+
+// ignoreRules combines gitignore patterns collected from the source directories
+// and root .dockerignore patterns
+type ignoreRules struct {
+	git    []gitignore.Pattern
+	docker *fileutils.PatternMatcher
+}
+
+func newIgnoreRules(root string) (*ignoreRules, error) {
+	r := &ignoreRules{
+		git: []gitignore.Pattern{
+			gitignore.ParsePattern(".git", nil),
+		},
+	}
+	if err := r.load(root, "."); err != nil {
+		return nil, err
+	}
+	patterns, err := readDockerignore(filepath.Join(root, dockerignoreFile))
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) != 0 {
+		if r.docker, err = fileutils.NewPatternMatcher(patterns); err != nil {
+			return nil, fmt.Errorf("parsing %s: %s", dockerignoreFile, err)
+		}
+	}
+	return r, nil
+}
+
+// load reads gitignore files of the directory, patterns are scoped to the directory content
+func (r *ignoreRules) load(root, dir string) error {
+	var domain []string
+	if dir != "." {
+		domain = strings.Split(filepath.ToSlash(dir), "/")
+	}
+	for _, name := range gitignoreFiles {
+		path := filepath.Join(root, dir, name)
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			r.git = append(r.git, gitignore.ParsePattern(line, domain))
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("reading %s: %s", path, err)
+		}
+	}
+	return nil
+}
+
+// match reports whether the path relative to the sources root is ignored.
+// Ignored directory is still walked in if .dockerignore has exclusion
+// patterns which may bring back some of its files.
+func (r *ignoreRules) match(rel string, isDir bool) (ignored, walkIn bool, err error) {
+	if gitignore.NewMatcher(r.git).Match(strings.Split(filepath.ToSlash(rel), "/"), isDir) {
+		return true, false, nil
+	}
+	if r.docker == nil {
+		return false, false, nil
+	}
+	if ignored, err = r.docker.Matches(rel); err != nil {
+		return false, false, err
+	}
+	return ignored, ignored && isDir && r.docker.Exclusions(), nil
+}
+
+// readDockerignore returns .dockerignore patterns normalized the same way docker does it
+func readDockerignore(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		pattern := strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		invert := pattern[0] == '!'
+		if invert {
+			pattern = strings.TrimSpace(pattern[1:])
+		}
+		if pattern != "" {
+			pattern = filepath.ToSlash(filepath.Clean(pattern))
+			if len(pattern) > 1 && pattern[0] == '/' {
+				pattern = pattern[1:]
+			}
+		}
+		if invert {
+			pattern = "!" + pattern
+		}
+		patterns = append(patterns, pattern)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %s", dockerignoreFile, err)
+	}
+	return patterns, nil
+}
+
+// write packs archive files into the tar stream compressed with provided algorithm.
+// Regular file contents are passed through the counter to track the upload progress.
+func (a *archive) write(w io.Writer, compression string, counter func(int)) error {
+	var cw io.WriteCloser
+	switch compression {
+	case CompressionGzip, "":
+		cw = gzip.NewWriter(w)
+	case CompressionZstd:
+		var err error
+		if cw, err = zstd.NewWriter(w); err != nil {
+			return err
+		}
+	case CompressionNone:
+		cw = nopWriteCloser{w}
+	default:
+		return fmt.Errorf("unsupported compression %q", compression)
+	}
+
+	tw := tar.NewWriter(cw)
+	for _, f := range a.files {
+		header, err := tar.FileInfoHeader(f.info, f.target)
+		if err != nil {
+			return err
+		}
+		header.Name = f.name
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !f.info.Mode().IsRegular() {
+			continue
+		}
+		if err := copyFile(tw, f.path, counter); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return cw.Close()
+}
+
+func copyFile(w io.Writer, path string, counter func(int)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			if counter != nil {
+				counter(n)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
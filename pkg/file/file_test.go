@@ -1,9 +1,18 @@
 package file
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
 	"testing"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // ParseServerlessYAML accepts serverless yaml file path and returns decoded structure
@@ -106,7 +115,7 @@ func TestIsRegistry(t *testing.T) {
 
 // Clone runs `git clone` operation for specified URL and returns local path to repository root directory
 // func TestClone(t *testing.T) {
-// 	path, err := Clone("https://github.com/triggermesh/tm")
+// 	path, err := Clone("https://github.com/triggermesh/tm", nil)
 // 	assert.NoError(t, err)
 
 // 	if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -114,3 +123,122 @@ func TestIsRegistry(t *testing.T) {
 // 	}
 // 	os.Remove(path)
 // }
+
+func TestArchive(t *testing.T) {
+	dir, err := os.MkdirTemp("", "tm-archive-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "function")
+	files := map[string]string{
+		"main.go":             "package main",
+		"vendor/lib/lib.go":   "package lib",
+		"build/output.bin":    "binary",
+		"debug.log":           "log",
+		".git/HEAD":           "ref",
+		".tmignore":           "# comment\nvendor/\n*.log\n",
+		".dockerignore":       "build\ndocs\n!docs/README.md\n",
+		"handler/handler.go":  "package handler",
+		"handler/handler.log": "log",
+		// nested gitignore applies to its directory only
+		"handler/.gitignore": "*.tmp\n",
+		"handler/cache.tmp":  "tmp",
+		"cache.tmp":          "tmp",
+		// dockerignore patterns are anchored to the sources root
+		"handler/build/output.bin": "binary",
+		"docs/guide.md":            "guide",
+		"docs/README.md":           "readme",
+	}
+	for name, data := range files {
+		path := filepath.Join(source, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+	}
+
+	a, err := newArchive(source)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	var uploaded int
+	require.NoError(t, a.write(&buf, CompressionGzip, func(n int) { uploaded += n }))
+	assert.Equal(t, a.size, int64(uploaded))
+
+	gz, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if header.Typeflag == tar.TypeReg {
+			names = append(names, header.Name)
+		}
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{
+		"function/.dockerignore",
+		"function/.tmignore",
+		"function/cache.tmp",
+		"function/docs/README.md",
+		"function/handler/.gitignore",
+		"function/handler/build/output.bin",
+		"function/handler/handler.go",
+		"function/main.go",
+	}, names)
+}
+
+func TestArchiveCompression(t *testing.T) {
+	dir, err := os.MkdirTemp("", "tm-archive-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	source := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(source, []byte("package main"), 0644))
+
+	a, err := newArchive(source)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, a.write(&buf, CompressionZstd, nil))
+	zr, err := zstd.NewReader(&buf)
+	require.NoError(t, err)
+	defer zr.Close()
+	header, err := tar.NewReader(zr).Next()
+	require.NoError(t, err)
+	assert.Equal(t, "main.go", header.Name)
+
+	assert.EqualError(t, a.write(&buf, "lz4", nil), `unsupported compression "lz4"`)
+}
+
+func TestCheckSize(t *testing.T) {
+	dir, err := os.MkdirTemp("", "tm-archive-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), make([]byte, 2048), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".tmignore"), []byte("vendor\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "vendor"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "vendor", "lib.go"), make([]byte, 4096), 0644))
+
+	assert.NoError(t, CheckSize(dir, 0))
+	// ignored files are not counted
+	assert.NoError(t, CheckSize(dir, 3*1024))
+	assert.EqualError(t, CheckSize(dir, 1024), "sources size 2.0KiB exceeds upload limit 1.0KiB, exclude unneeded files with .tmignore or increase the limit")
+}
+
+func TestHumanBytes(t *testing.T) {
+	testCases := []struct {
+		size   int64
+		result string
+	}{
+		{0, "0B"},
+		{1023, "1023B"},
+		{1024, "1.0KiB"},
+		{5 * 1024 * 1024, "5.0MiB"},
+		{3 * 1024 * 1024 * 1024 / 2, "1.5GiB"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.result, HumanBytes(tc.size))
+	}
+}
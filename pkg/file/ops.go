@@ -21,10 +21,12 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"strings"
 	"time"
 
 	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 )
 
 const (
@@ -176,18 +178,30 @@ func Download(url string) (string, error) {
 	return path, nil
 }
 
-// Clone runs `git clone` operation for specified URL and returns local path to repository root directory
-func Clone(url string) (string, error) {
+// Clone runs `git clone` operation for specified URL and returns local path to repository root directory.
+// Auth may be nil for public repositories.
+func Clone(url string, auth transport.AuthMethod) (string, error) {
 	path := fmt.Sprintf("%s/git/%s", tmpPath, RandString(10))
 	if err := os.MkdirAll(path, os.ModePerm); err != nil {
 		return "", err
 	}
 	_, err := git.PlainClone(path, false, &git.CloneOptions{
-		URL: url,
+		URL:  url,
+		Auth: auth,
 	})
 	return path, err
 }
 
+// TempDirs returns local directories where tm keeps downloaded files, git clones
+// and, in older versions, packed sources uploads
+func TempDirs() []string {
+	return []string{
+		path.Join(tmpPath, "download"),
+		path.Join(tmpPath, "git"),
+		path.Join(tmpPath, "upload"),
+	}
+}
+
 // Write creates file named as passed filename and writes data into this file
 func Write(filename, data string) error {
 	f, err := os.Create(filename)
@@ -18,15 +18,18 @@ package file
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
+	"net/url"
 	"path"
 	"strings"
+	"time"
 
-	"github.com/mholt/archiver"
 	"github.com/triggermesh/tm/pkg/client"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/client-go/tools/remotecommand"
 )
 
@@ -37,39 +40,122 @@ type Copy struct {
 	Namespace   string
 	Source      string
 	Destination string
+	// Compression algorithm of the upload stream, "gzip", "zstd" or "none"
+	Compression string
+	// SizeLimit is the maximum size of sources in bytes, 0 means no limit
+	SizeLimit int64
+	// Retries is the number of attempts to restart upload on transient stream failures
+	Retries int
+	// Progress, if set, is used to render upload progress bar
+	Progress io.Writer
 }
 
-// Upload receives Copy structure, creates tarball of local source path and uploads it to active (un)tar process on remote pod
+// Upload receives Copy structure, packs local source path into compressed tar stream
+// and uploads it to active (un)tar process on remote pod.
+// Files matching patterns in .tmignore, .gitignore and root .dockerignore are skipped.
 func (c *Copy) Upload(clientset *client.ConfigSet) error {
-	uploadPath := "/tmp/tm/upload"
-	if err := os.MkdirAll(uploadPath, os.ModePerm); err != nil {
+	sources, err := newArchive(c.Source)
+	if err != nil {
+		return fmt.Errorf("reading sources: %s", err)
+	}
+	if err := sources.checkSize(c.SizeLimit); err != nil {
 		return err
 	}
+	clientset.Log.Debugf("uploading %d files, %s total", len(sources.files), HumanBytes(sources.size))
 
-	tar := path.Join(uploadPath, RandString(10)+".tar")
-	if err := archiver.DefaultTar.Archive([]string{c.Source}, tar); err != nil {
-		return err
+	command := []string{"tar", "-xzvf", "-", "-C", "/home"}
+	switch c.Compression {
+	case CompressionGzip, "":
+	case CompressionZstd:
+		// requires GNU tar 1.31+ and zstd binary in the build pod
+		command = []string{"tar", "--zstd", "-xvf", "-", "-C", "/home"}
+	case CompressionNone:
+		command = []string{"tar", "-xvf", "-", "-C", "/home"}
+	default:
+		return fmt.Errorf("unsupported upload compression %q, use %q, %q or %q", c.Compression, CompressionGzip, CompressionZstd, CompressionNone)
+	}
+
+	var bar *progress
+	if c.Progress != nil {
+		bar = newProgress(c.Progress, "Uploading "+path.Base(c.Source), sources.size)
 	}
-	clientset.Log.Debugf("sources are packed into %q archive, opening reader\n", tar)
 
-	fileReader, err := os.Open(tar)
+	for attempt := 0; ; attempt++ {
+		bar.reset()
+		reader, writer := io.Pipe()
+		archiveErr := make(chan error, 1)
+		go func() {
+			err := sources.write(writer, c.Compression, bar.add)
+			writer.CloseWithError(err)
+			archiveErr <- err
+		}()
+
+		clientset.Log.Debugf("starting remote untar proccess")
+		var stdout, stderr string
+		stdout, stderr, err = c.exec(clientset, command, reader)
+		reader.Close()
+		clientset.Log.Debugf("stdout:\n%s", stdout)
+		clientset.Log.Debugf("stderr:\n%s", stderr)
+		if aErr := <-archiveErr; aErr != nil && !errors.Is(aErr, io.ErrClosedPipe) {
+			return fmt.Errorf("packing sources: %s", aErr)
+		}
+		if err == nil || attempt >= c.Retries || !isTransient(err) {
+			break
+		}
+		clientset.Log.Warnf("Upload stream failed: %s, retrying (%d/%d)", err, attempt+1, c.Retries)
+		select {
+		case <-time.After(time.Duration(attempt+1) * time.Second):
+		case <-clientset.Context.Done():
+			return clientset.Context.Err()
+		}
+	}
 	if err != nil {
 		return err
 	}
+	bar.done()
+	return nil
+}
+
+// CheckSize returns an error if the sources uploaded from the source path
+// exceed the size limit in bytes, 0 means no limit
+func CheckSize(source string, limit int64) error {
+	if limit <= 0 {
+		return nil
+	}
+	sources, err := newArchive(source)
+	if err != nil {
+		return fmt.Errorf("reading sources: %s", err)
+	}
+	return sources.checkSize(limit)
+}
 
-	clientset.Log.Debugf("starting remote untar proccess")
-	command := fmt.Sprintf("tar -xvf - -C /home")
-	stdout, stderr, err := c.RemoteExec(clientset, command, fileReader)
-	clientset.Log.Debugf("stdout:\n%s", stdout)
-	clientset.Log.Debugf("stderr:\n%s", stderr)
-	return err
+func (a *archive) checkSize(limit int64) error {
+	if limit > 0 && a.size > limit {
+		return fmt.Errorf("sources size %s exceeds upload limit %s, exclude unneeded files with .tmignore or increase the limit",
+			HumanBytes(a.size), HumanBytes(limit))
+	}
+	return nil
+}
+
+// isTransient returns true if stream error is likely caused by network issue
+// and the upload can be restarted
+func isTransient(err error) bool {
+	return utilnet.IsConnectionReset(err) ||
+		utilnet.IsProbableEOF(err) ||
+		k8serrors.IsTimeout(err) ||
+		k8serrors.IsServerTimeout(err) ||
+		k8serrors.IsTooManyRequests(err)
 }
 
 // RemoteExec executes command on remote pod and returns stdout and stderr output
 func (c *Copy) RemoteExec(clientset *client.ConfigSet, command string, file io.Reader) (string, string, error) {
+	return c.exec(clientset, strings.Fields(command), file)
+}
+
+func (c *Copy) exec(clientset *client.ConfigSet, command []string, file io.Reader) (string, string, error) {
 	var commandLine string
-	for _, v := range strings.Fields(command) {
-		commandLine = fmt.Sprintf("%s&command=%s", commandLine, v)
+	for _, v := range command {
+		commandLine = fmt.Sprintf("%s&command=%s", commandLine, url.QueryEscape(v))
 	}
 	if c.Container != "" {
 		commandLine = fmt.Sprintf("&container=%s%s", c.Container, commandLine)
@@ -79,13 +165,13 @@ func (c *Copy) RemoteExec(clientset *client.ConfigSet, command string, file io.R
 		stdin = "true"
 	}
 	// workaround to form correct URL
-	urlAndParams := strings.Split(clientset.Core.RESTClient().Post().URL().String(), "?")
-	url := fmt.Sprintf("%sapi/v1/namespaces/%s/pods/%s/exec?stderr=true&stdin=%s&stdout=true%s", urlAndParams[0], c.Namespace, c.Pod, stdin, commandLine)
+	urlAndParams := strings.Split(clientset.Core.Discovery().RESTClient().Post().URL().String(), "?")
+	execURL := fmt.Sprintf("%sapi/v1/namespaces/%s/pods/%s/exec?stderr=true&stdin=%s&stdout=true%s", urlAndParams[0], c.Namespace, c.Pod, stdin, commandLine)
 	if len(urlAndParams) == 2 {
-		url = fmt.Sprintf("%s&%s", url, urlAndParams[1])
+		execURL = fmt.Sprintf("%s&%s", execURL, urlAndParams[1])
 	}
-	clientset.Log.Debugf("remote exec request URL: %q", url)
-	req, err := http.NewRequest("POST", url, nil)
+	clientset.Log.Debugf("remote exec request URL: %q", execURL)
+	req, err := http.NewRequest("POST", execURL, nil)
 	if err != nil {
 		return "", "", err
 	}
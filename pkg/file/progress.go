@@ -0,0 +1,112 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const progressBarWidth = 30
+
+// progress renders single line upload progress bar with transferred bytes and ETA
+type progress struct {
+	sync.Mutex
+	out     io.Writer
+	title   string
+	total   int64
+	current int64
+	started time.Time
+	printed time.Time
+}
+
+func newProgress(out io.Writer, title string, total int64) *progress {
+	return &progress{
+		out:     out,
+		title:   title,
+		total:   total,
+		started: time.Now(),
+	}
+}
+
+// reset starts progress from the beginning, e.g. when upload is retried
+func (p *progress) reset() {
+	if p == nil {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.current = 0
+	p.started = time.Now()
+}
+
+func (p *progress) add(n int) {
+	if p == nil {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.current += int64(n)
+	// do not redraw the bar more often than 10 times per second
+	if time.Since(p.printed) < 100*time.Millisecond && p.current < p.total {
+		return
+	}
+	p.printed = time.Now()
+	p.render()
+}
+
+func (p *progress) done() {
+	if p == nil {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.current = p.total
+	p.render()
+	fmt.Fprintln(p.out)
+}
+
+func (p *progress) render() {
+	ratio := 1.0
+	if p.total > 0 {
+		ratio = float64(p.current) / float64(p.total)
+	}
+	filled := int(ratio * progressBarWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+
+	eta := "--"
+	if elapsed := time.Since(p.started); p.current > 0 && elapsed > 0 {
+		left := time.Duration(float64(elapsed) * (float64(p.total-p.current) / float64(p.current)))
+		eta = left.Round(time.Second).String()
+	}
+	fmt.Fprintf(p.out, "\r%s [%s] %s/%s %3.0f%% ETA %s ", p.title, bar, HumanBytes(p.current), HumanBytes(p.total), ratio*100, eta)
+}
+
+// HumanBytes returns size in bytes in human readable format, e.g. "12.3MiB"
+func HumanBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v2"
@@ -17,18 +19,38 @@ type Definition struct {
 	Repository  string              `yaml:"repository,omitempty"`
 	Functions   map[string]Function `yaml:"functions,omitempty"`
 	Include     []string            `yaml:"include,omitempty"`
+	Secrets     map[string]Secret   `yaml:"secrets,omitempty"`
+}
+
+// Secret describes k8s Secret which values are read from local sources.
+// Relative paths are resolved against the manifest directory.
+type Secret struct {
+	// EnvFile is the path to .env file, all its variables are stored in the secret
+	EnvFile string `yaml:"env-file,omitempty"`
+	// Env is the list of local environment variables stored in the secret
+	// as "NAME" or "KEY=NAME" to store variable NAME under the different key
+	Env []string `yaml:"env,omitempty"`
+	// Files maps secret keys to the local file paths
+	Files map[string]string `yaml:"files,omitempty"`
+	// Data contains secret values, normally encrypted with "tm secret encrypt"
+	Data map[string]string `yaml:"data,omitempty"`
 }
 
 // TriggermeshProvider structure contains serverless provider parameters specific to triggermesh
 type TriggermeshProvider struct {
-	Name         string            `yaml:"name,omitempty"`
-	PullPolicy   string            `yaml:"pull-policy,omitempty"`
-	Namespace    string            `yaml:"namespace,omitempty"`
-	Runtime      string            `yaml:"runtime,omitempty"`
-	Buildtimeout string            `yaml:"buildtimeout,omitempty"`
-	Environment  map[string]string `yaml:"environment,omitempty"`
-	EnvSecrets   []string          `yaml:"env-secrets,omitempty"`
-	Annotations  map[string]string `yaml:"annotations,omitempty"`
+	Name         string `yaml:"name,omitempty"`
+	PullPolicy   string `yaml:"pull-policy,omitempty"`
+	Namespace    string `yaml:"namespace,omitempty"`
+	Runtime      string `yaml:"runtime,omitempty"`
+	Buildtimeout string `yaml:"buildtimeout,omitempty"`
+	// WorkspaceSize is the size of the volume claim to clone git sources into
+	WorkspaceSize string            `yaml:"workspace-size,omitempty"`
+	Cache         *Cache            `yaml:"cache,omitempty"`
+	Environment   map[string]string `yaml:"environment,omitempty"`
+	EnvSecrets    []string          `yaml:"env-secrets,omitempty"`
+	Annotations   map[string]string `yaml:"annotations,omitempty"`
+	// ServiceAccount is the default account of functions and their builds
+	ServiceAccount string `yaml:"serviceAccount,omitempty"`
 
 	// registry configs moved to client Configset
 	// these variables kept for backward compatibility
@@ -43,13 +65,81 @@ type Function struct {
 	Revision    string            `yaml:"revision,omitempty"`
 	Runtime     string            `yaml:"runtime,omitempty"`
 	Concurrency int               `yaml:"concurrency,omitempty"`
-	Buildargs   []string          `yaml:"buildargs,omitempty"`
+	Buildargs   BuildArgs         `yaml:"buildargs,omitempty"`
 	Description string            `yaml:"description,omitempty"`
 	Labels      []string          `yaml:"labels,omitempty"`
 	Environment map[string]string `yaml:"environment,omitempty"`
 	EnvSecrets  []string          `yaml:"env-secrets,omitempty"`
 	Annotations map[string]string `yaml:"annotations,omitempty"`
 	Schedule    []Schedule        `yaml:"schedule,omitempty"`
+	Cache       *Cache            `yaml:"cache,omitempty"`
+	// SecretMounts maps secret names to the directories where secrets are mounted as files
+	SecretMounts map[string]string `yaml:"secret-mounts,omitempty"`
+	// ServiceAccount overrides provider's account
+	ServiceAccount string `yaml:"serviceAccount,omitempty"`
+	// Permissions are granted to the dedicated function ServiceAccount created by tm
+	Permissions []Permission `yaml:"permissions,omitempty"`
+}
+
+// Permission is the namespaced RBAC policy rule
+type Permission struct {
+	APIGroups     []string `yaml:"apiGroups,omitempty"`
+	Resources     []string `yaml:"resources,omitempty"`
+	ResourceNames []string `yaml:"resourceNames,omitempty"`
+	Verbs         []string `yaml:"verbs"`
+}
+
+// BuildArgs is a list of "NAME=VALUE" image build arguments.
+// Manifest may also declare arguments as "NAME: VALUE" maps, where VALUE is a string
+// or a list of strings for array task params. Each list item is converted into
+// a separate "NAME=ITEM" argument.
+type BuildArgs []string
+
+// UnmarshalYAML implements yaml.Unmarshaler interface
+func (b *BuildArgs) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var items []interface{}
+	if err := unmarshal(&items); err != nil {
+		return err
+	}
+	var args BuildArgs
+	for _, item := range items {
+		switch item := item.(type) {
+		case map[interface{}]interface{}:
+			var names []string
+			for name := range item {
+				names = append(names, fmt.Sprintf("%v", name))
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				switch value := item[name].(type) {
+				case []interface{}:
+					for _, v := range value {
+						args = append(args, fmt.Sprintf("%s=%v", name, v))
+					}
+				case map[interface{}]interface{}:
+					return fmt.Errorf("build argument %q: object values are not supported", name)
+				default:
+					args = append(args, fmt.Sprintf("%s=%v", name, value))
+				}
+			}
+		case []interface{}, nil:
+			return fmt.Errorf("unsupported build argument %v", item)
+		default:
+			args = append(args, fmt.Sprintf("%v", item))
+		}
+	}
+	*b = args
+	return nil
+}
+
+// Cache describes where image build cache is stored between deployments:
+// registry repository for kaniko layers cache and/or persistent volume claim
+// mounted into the build steps.
+type Cache struct {
+	Repository string `yaml:"repository,omitempty"`
+	Volume     string `yaml:"volume,omitempty"`
+	Size       string `yaml:"size,omitempty"`
+	AccessMode string `yaml:"accessMode,omitempty"`
 }
 
 // Schedule struct contains a data in JSON format and a cron
@@ -61,6 +151,34 @@ type Schedule struct {
 	Description string
 }
 
+// ParseEnvFile reads variables from .env file. Empty lines and comments are skipped,
+// optional "export" prefix and values quotes are removed.
+func ParseEnvFile(path string) (map[string]string, error) {
+	data, err := afero.ReadFile(Aos, path)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		kv := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, i+1)
+		}
+		value := strings.TrimSpace(kv[1])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
 // Aos returns filesystem object with standard set of os methods implemented by afero package
 var Aos = afero.NewOsFs()
 
@@ -36,3 +36,55 @@ func TestParseManifestInvalid(t *testing.T) {
 	assert.Contains(t, err.Error(), "yaml: unmarshal errors")
 	assert.Empty(t, definition.Service)
 }
+
+func TestBuildArgs(t *testing.T) {
+	Aos = afero.NewMemMapFs()
+
+	manifest := `service: foo
+functions:
+  bar:
+    source: main.go
+    buildargs:
+    - HANDLER=main.handler
+    - DIRECTORY: src
+    - EXTRA_ARGS:
+      - --foo
+      - --bar=baz
+`
+	err := afero.WriteFile(Aos, "my-file.yml", []byte(manifest), 664)
+	require.NoError(t, err)
+
+	definition, err := ParseManifest("my-file.yml")
+	require.NoError(t, err)
+	assert.Equal(t, BuildArgs{
+		"HANDLER=main.handler",
+		"DIRECTORY=src",
+		"EXTRA_ARGS=--foo",
+		"EXTRA_ARGS=--bar=baz",
+	}, definition.Functions["bar"].Buildargs)
+}
+
+func TestParseEnvFile(t *testing.T) {
+	Aos = afero.NewMemMapFs()
+	data := `# database credentials
+DB_USER=admin
+export DB_PASSWORD="s3cr=t"
+
+API_KEY='foo bar'
+EMPTY=
+`
+	require.NoError(t, afero.WriteFile(Aos, ".env", []byte(data), 0600))
+
+	env, err := ParseEnvFile(".env")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"DB_USER":     "admin",
+		"DB_PASSWORD": "s3cr=t",
+		"API_KEY":     "foo bar",
+		"EMPTY":       "",
+	}, env)
+
+	require.NoError(t, afero.WriteFile(Aos, "invalid.env", []byte("FOO=bar\nbaz\n"), 0600))
+	_, err = ParseEnvFile("invalid.env")
+	assert.EqualError(t, err, "invalid.env:2: expected KEY=VALUE")
+}
@@ -1,9 +1,17 @@
 package log
 
 import (
+	"fmt"
+
 	"github.com/sirupsen/logrus"
 )
 
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
 // Event stores messages to log later, from our standard interface
 type Event struct {
 	id      int
@@ -50,9 +58,28 @@ func (l *StandardLogger) MissingArg(argumentName string) {
 	l.Errorf(missingArgMessage.message, argumentName)
 }
 
+// SetFormat switches log output between text and JSON formats
+func (l *StandardLogger) SetFormat(format string) error {
+	switch format {
+	case FormatText, "":
+		l.Formatter = &logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "15:04:05",
+		}
+	case FormatJSON:
+		l.Formatter = &logrus.JSONFormatter{}
+	default:
+		return fmt.Errorf("unknown log format %q, supported formats: %s, %s", format, FormatText, FormatJSON)
+	}
+	return nil
+}
+
 func (l *StandardLogger) SetDebugLevel() {
-	l.Formatter = &logrus.TextFormatter{
-		FullTimestamp: true,
+	// JSON records already contain full timestamps
+	if _, ok := l.Formatter.(*logrus.TextFormatter); ok {
+		l.Formatter = &logrus.TextFormatter{
+			FullTimestamp: true,
+		}
 	}
 	l.SetLevel(logrus.DebugLevel)
 }
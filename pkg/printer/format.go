@@ -0,0 +1,228 @@
+// Copyright 2020 TriggerMesh, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Output formats that are not printed as the table or the short object view
+const (
+	FormatYAML          = "yaml"
+	FormatJSON          = "json"
+	FormatWide          = "wide"
+	FormatName          = "name"
+	FormatJSONPath      = "jsonpath"
+	FormatGoTemplate    = "go-template"
+	FormatCustomColumns = "custom-columns"
+)
+
+// parseFormat splits output format into its name and argument, e.g. "jsonpath={.metadata.name}"
+func parseFormat(format string) (string, string, error) {
+	name, arg := format, ""
+	if i := strings.Index(format, "="); i != -1 {
+		name, arg = format[:i], format[i+1:]
+	}
+	switch name {
+	case "", FormatYAML, FormatJSON, FormatWide, FormatName:
+		if arg != "" {
+			return "", "", fmt.Errorf("output format %q does not accept arguments", name)
+		}
+	case FormatJSONPath, FormatGoTemplate, FormatCustomColumns:
+		if arg == "" {
+			return "", "", fmt.Errorf("output format %q requires template, e.g. %s=...", name, name)
+		}
+	default:
+		return "", "", fmt.Errorf("unknown output format %q, supported formats: yaml, json, wide, name, jsonpath=..., go-template=..., custom-columns=...", format)
+	}
+	return name, arg, nil
+}
+
+// PrintList prints list of k8s objects as the table or, if output format
+// requires it, encodes the list object itself. Table rows must follow list items order
+func (p *Printer) PrintList(list interface{}, table Table) error {
+	format, arg, err := parseFormat(p.Format)
+	if err != nil {
+		return err
+	}
+	if table, err = p.sortList(list, table); err != nil {
+		return err
+	}
+	switch format {
+	case "", FormatWide:
+		if !p.AllNamespaces {
+			table = withoutNamespace(table)
+		}
+		p.PrintTable(table)
+		return nil
+	case FormatName, FormatCustomColumns:
+		items, err := listItems(list)
+		if err != nil {
+			return err
+		}
+		if format == FormatName {
+			return p.printNames(items...)
+		}
+		return p.printColumns(arg, items...)
+	}
+	return p.PrintObject(Object{K8sObject: list})
+}
+
+// printEncoded prints object in one of the template formats
+func (p *Printer) printEncoded(format, arg string, object interface{}) error {
+	switch format {
+	case FormatName:
+		return p.printNames(object)
+	case FormatCustomColumns:
+		return p.printColumns(arg, object)
+	}
+	data, err := toJSONValue(object)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatJSONPath:
+		parser := jsonpath.New("output").AllowMissingKeys(true)
+		if err := parser.Parse(relaxedJSONPath(arg)); err != nil {
+			return fmt.Errorf("parsing jsonpath %q: %s", arg, err)
+		}
+		if err := parser.Execute(p.Output, data); err != nil {
+			return err
+		}
+	case FormatGoTemplate:
+		tmpl, err := template.New("output").Parse(arg)
+		if err != nil {
+			return fmt.Errorf("parsing go-template %q: %s", arg, err)
+		}
+		if err := tmpl.Execute(p.Output, data); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(p.Output)
+	return nil
+}
+
+// printNames prints objects as "kind/name"
+func (p *Printer) printNames(objects ...interface{}) error {
+	for _, object := range objects {
+		accessor, err := meta.Accessor(object)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(p.Output, "%s/%s\n", kind(object), accessor.GetName())
+	}
+	return nil
+}
+
+// printColumns prints objects as a table with columns defined in "HEADER:jsonpath,..." format
+func (p *Printer) printColumns(spec string, objects ...interface{}) error {
+	var headers []string
+	var parsers []*jsonpath.JSONPath
+	for _, column := range strings.Split(spec, ",") {
+		parts := strings.SplitN(column, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("custom column %q must be in HEADER:jsonpath format", column)
+		}
+		parser := jsonpath.New(parts[0]).AllowMissingKeys(true)
+		if err := parser.Parse(relaxedJSONPath(parts[1])); err != nil {
+			return fmt.Errorf("parsing column %q jsonpath: %s", parts[0], err)
+		}
+		headers = append(headers, parts[0])
+		parsers = append(parsers, parser)
+	}
+	table := Table{
+		Headers: headers,
+		Rows:    make([][]string, 0, len(objects)),
+	}
+	for _, object := range objects {
+		data, err := toJSONValue(object)
+		if err != nil {
+			return err
+		}
+		row := make([]string, len(parsers))
+		for i, parser := range parsers {
+			var value bytes.Buffer
+			if err := parser.Execute(&value, data); err != nil {
+				return err
+			}
+			row[i] = value.String()
+			if row[i] == "" {
+				row[i] = "<none>"
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	p.PrintTable(table)
+	return nil
+}
+
+// listItems returns items of k8s list object
+func listItems(list interface{}) ([]interface{}, error) {
+	object, ok := list.(runtime.Object)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a k8s list", list)
+	}
+	items, err := meta.ExtractList(object)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// kind returns lowercase object kind, typed clients leave TypeMeta empty
+// so it is taken from the object type name
+func kind(object interface{}) string {
+	if typed, ok := object.(runtime.Object); ok {
+		if kind := typed.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+			return strings.ToLower(kind)
+		}
+	}
+	return strings.ToLower(reflect.Indirect(reflect.ValueOf(object)).Type().Name())
+}
+
+// toJSONValue converts object into generic structure so that templates
+// refer to the same field names as yaml and json outputs
+func toJSONValue(object interface{}) (interface{}, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	return value, json.Unmarshal(data, &value)
+}
+
+// relaxedJSONPath turns ".metadata.name" and "metadata.name" expressions into "{.metadata.name}"
+func relaxedJSONPath(expression string) string {
+	if strings.HasPrefix(expression, "{") {
+		return expression
+	}
+	if !strings.HasPrefix(expression, ".") {
+		expression = "." + expression
+	}
+	return "{" + expression + "}"
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 TriggerMesh, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPrintFormats(t *testing.T) {
+	configMap := func(name, value string) corev1.ConfigMap {
+		return corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Data:       map[string]string{"key": value},
+		}
+	}
+	list := &corev1.ConfigMapList{Items: []corev1.ConfigMap{configMap("foo", "1"), configMap("bar", "")}}
+	single := configMap("foo", "1")
+	table := Table{
+		Headers:     []string{"Name"},
+		WideHeaders: []string{"Key"},
+		Rows:        [][]string{{"foo", "1"}, {"bar", ""}},
+	}
+
+	testCases := []struct {
+		format string
+		list   string
+		object string
+		err    bool
+	}{
+		{format: "", list: "NAME\nfoo\nbar"},
+		{format: "wide", list: "NAME KEY\nfoo 1\nbar"},
+		{format: "name", list: "configmap/foo\nconfigmap/bar", object: "configmap/foo"},
+		{format: "jsonpath={.items[*].metadata.name}", list: "foo bar"},
+		{format: "jsonpath=.data.key", object: "1"},
+		{format: "go-template={{.metadata.namespace}}/{{.metadata.name}}", object: "ns/foo"},
+		{format: "custom-columns=NAME:.metadata.name,KEY:.data.key", list: "NAME KEY\nfoo 1\nbar <none>", object: "NAME KEY\nfoo 1"},
+		{format: "custom-columns=NAME", err: true},
+		{format: "jsonpath=", err: true},
+		{format: "table", err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			var out bytes.Buffer
+			p := NewPrinter(&out)
+			p.Format = tc.format
+			err := p.PrintList(list, table)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if tc.list != "" {
+				assert.Equal(t, tc.list, normalize(out.String()))
+			}
+			if tc.object == "" {
+				return
+			}
+			out.Reset()
+			p = NewPrinter(&out)
+			p.Format = tc.format
+			assert.NoError(t, p.PrintObject(Object{K8sObject: &single}))
+			assert.Equal(t, tc.object, normalize(out.String()))
+		})
+	}
+}
+
+// normalize removes table padding
+func normalize(output string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	return strings.Join(lines, "\n")
+}
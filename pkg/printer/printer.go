@@ -31,7 +31,10 @@ type rows [][]string
 // Table is a structure with list headers and data rows that can be printed in PrintTable method
 type Table struct {
 	Headers headers
-	Rows    rows
+	// WideHeaders are extra columns shown with "wide" output format,
+	// their values follow regular columns values in the rows
+	WideHeaders headers
+	Rows        rows
 }
 
 // Object is a structure that contain k8s object and field descriptions that should be printed
@@ -43,12 +46,23 @@ type Object struct {
 // Printer structure contains information needed to print objects in "tm get" command
 type Printer struct {
 	Format string
-	Output io.Writer
-	Table  *tablewriter.Table
+	// SortBy is the table column name or JSONPath expression to sort lists by
+	SortBy string
+	// AllNamespaces adds namespace column to the lists
+	AllNamespaces bool
+	Output        io.Writer
+	Table         *tablewriter.Table
 }
 
 // NewPrinter returns new Printer instance
 func NewPrinter(out io.Writer) *Printer {
+	return &Printer{
+		Output: out,
+		Table:  newTableWriter(out),
+	}
+}
+
+func newTableWriter(out io.Writer) *tablewriter.Table {
 	table := tablewriter.NewWriter(out)
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
 	table.SetBorder(false)
@@ -57,10 +71,7 @@ func NewPrinter(out io.Writer) *Printer {
 	table.SetColumnSeparator("")
 	table.SetNoWhiteSpace(true)
 	table.SetTablePadding("\t")
-	return &Printer{
-		Output: out,
-		Table:  table,
-	}
+	return table
 }
 
 func (p *Printer) setTableHeaders(heads headers) {
@@ -71,26 +82,44 @@ func (p *Printer) setTableHeaders(heads headers) {
 
 // PrintTable accepts Table instance and prints it using olekukonko/tablewriter package
 func (p *Printer) PrintTable(table Table) {
-	p.setTableHeaders(table.Headers)
-	p.Table.AppendBulk(table.Rows)
+	heads, data := table.Headers, table.Rows
+	if p.Format == FormatWide {
+		heads = append(append(headers{}, table.Headers...), table.WideHeaders...)
+	} else if len(table.WideHeaders) != 0 {
+		data = make(rows, 0, len(table.Rows))
+		for _, row := range table.Rows {
+			if len(row) > len(heads) {
+				row = row[:len(heads)]
+			}
+			data = append(data, row)
+		}
+	}
+	p.setTableHeaders(heads)
+	p.Table.AppendBulk(data)
 	p.Table.Render()
 }
 
 // PrintObject accepts Object instance and depending on output format encodes object and writes to Object output
 func (p *Printer) PrintObject(object Object) error {
-	switch p.Format {
-	case "yaml":
+	format, arg, err := parseFormat(p.Format)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatYAML:
 		data, err := yaml.Marshal(object.K8sObject)
 		if err != nil {
 			return err
 		}
 		fmt.Fprintf(p.Output, "%s", data)
-	case "json":
+	case FormatJSON:
 		data, err := json.MarshalIndent(object.K8sObject, "", "  ")
 		if err != nil {
 			return err
 		}
 		fmt.Fprintf(p.Output, "%s", data)
+	case FormatName, FormatJSONPath, FormatGoTemplate, FormatCustomColumns:
+		return p.printEncoded(format, arg, object.K8sObject)
 	default:
 		p.printShort(object)
 	}
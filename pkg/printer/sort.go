@@ -0,0 +1,156 @@
+// Copyright 2020 TriggerMesh, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const namespaceColumn = "Namespace"
+
+// sortList orders list items and table rows by SortBy value which is either
+// table column name or JSONPath expression. Rows must follow items order
+func (p *Printer) sortList(list interface{}, table Table) (Table, error) {
+	if p.SortBy == "" {
+		return table, nil
+	}
+	object, ok := list.(runtime.Object)
+	if !ok {
+		return table, fmt.Errorf("%T is not a k8s list", list)
+	}
+	items, err := meta.ExtractList(object)
+	if err != nil {
+		return table, err
+	}
+	if len(items) != len(table.Rows) {
+		return table, fmt.Errorf("cannot sort table with %d rows by %d objects", len(table.Rows), len(items))
+	}
+
+	keys, descending, err := p.sortKeys(items, table)
+	if err != nil {
+		return table, err
+	}
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if descending {
+			return less(keys[order[j]], keys[order[i]])
+		}
+		return less(keys[order[i]], keys[order[j]])
+	})
+
+	sortedItems := make([]runtime.Object, len(items))
+	sortedRows := make(rows, len(items))
+	for i, index := range order {
+		sortedItems[i] = items[index]
+		sortedRows[i] = table.Rows[index]
+	}
+	if err := meta.SetList(object, sortedItems); err != nil {
+		return table, err
+	}
+	table.Rows = sortedRows
+	return table, nil
+}
+
+// sortKeys returns values to sort objects by. Age column is sorted by
+// creation timestamp, newest objects first
+func (p *Printer) sortKeys(items []runtime.Object, table Table) ([]string, bool, error) {
+	expression, descending := p.SortBy, false
+	column := -1
+	for i, header := range append(append(headers{}, table.Headers...), table.WideHeaders...) {
+		if strings.EqualFold(header, p.SortBy) {
+			column = i
+			break
+		}
+	}
+	switch {
+	case strings.EqualFold(p.SortBy, "age"):
+		expression, descending = "{.metadata.creationTimestamp}", true
+	case column != -1:
+		keys := make([]string, len(table.Rows))
+		for i, row := range table.Rows {
+			if column < len(row) {
+				keys[i] = row[column]
+			}
+		}
+		return keys, false, nil
+	case !strings.HasPrefix(p.SortBy, ".") && !strings.HasPrefix(p.SortBy, "{"):
+		return nil, false, fmt.Errorf("cannot sort by %q: it is neither a column name nor a JSONPath expression", p.SortBy)
+	}
+
+	parser := jsonpath.New("sort-by").AllowMissingKeys(true)
+	if err := parser.Parse(relaxedJSONPath(expression)); err != nil {
+		return nil, false, fmt.Errorf("parsing sort-by %q: %s", p.SortBy, err)
+	}
+	keys := make([]string, len(items))
+	for i, item := range items {
+		data, err := toJSONValue(item)
+		if err != nil {
+			return nil, false, err
+		}
+		var value bytes.Buffer
+		if err := parser.Execute(&value, data); err != nil {
+			return nil, false, err
+		}
+		keys[i] = value.String()
+	}
+	return keys, descending, nil
+}
+
+// less compares values as numbers if both of them are numeric
+func less(a, b string) bool {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return x < y
+	}
+	return a < b
+}
+
+// withoutNamespace removes namespace column from the table
+func withoutNamespace(table Table) Table {
+	column := -1
+	for i, header := range table.Headers {
+		if header == namespaceColumn {
+			column = i
+			break
+		}
+	}
+	if column == -1 {
+		return table
+	}
+	result := Table{
+		Headers:     append(append(headers{}, table.Headers[:column]...), table.Headers[column+1:]...),
+		WideHeaders: table.WideHeaders,
+		Rows:        make(rows, 0, len(table.Rows)),
+	}
+	for _, row := range table.Rows {
+		if column < len(row) {
+			row = append(append([]string{}, row[:column]...), row[column+1:]...)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 TriggerMesh, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSortList(t *testing.T) {
+	now := time.Now()
+	pod := func(name, restarts string, age time.Duration) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "ns-" + name,
+			CreationTimestamp: metav1.Time{Time: now.Add(-age)},
+			Labels:            map[string]string{"restarts": restarts},
+		}}
+	}
+	testCases := []struct {
+		sortBy   string
+		expected []string
+		err      bool
+	}{
+		{sortBy: "", expected: []string{"b", "c", "a"}},
+		{sortBy: "name", expected: []string{"a", "b", "c"}},
+		{sortBy: "Restarts", expected: []string{"a", "c", "b"}},
+		{sortBy: "age", expected: []string{"a", "c", "b"}},
+		{sortBy: ".metadata.name", expected: []string{"a", "b", "c"}},
+		{sortBy: "{.metadata.labels.restarts}", expected: []string{"a", "c", "b"}},
+		{sortBy: "unknown", err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.sortBy, func(t *testing.T) {
+			list := &corev1.PodList{Items: []corev1.Pod{
+				pod("b", "10", 3*time.Hour),
+				pod("c", "9", time.Hour),
+				pod("a", "2", time.Minute),
+			}}
+			table := Table{Headers: []string{"Namespace", "Name", "Restarts"}}
+			for _, item := range list.Items {
+				table.Rows = append(table.Rows, []string{item.Namespace, item.Name, item.Labels["restarts"]})
+			}
+			p := NewPrinter(nil)
+			p.SortBy = tc.sortBy
+			sorted, err := p.sortList(list, table)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			for i, name := range tc.expected {
+				assert.Equal(t, name, list.Items[i].Name)
+				assert.Equal(t, name, sorted.Rows[i][1])
+			}
+		})
+	}
+}
+
+func TestWithoutNamespace(t *testing.T) {
+	table := withoutNamespace(Table{
+		Headers:     []string{"Namespace", "Name"},
+		WideHeaders: []string{"Image"},
+		Rows:        [][]string{{"default", "foo", "gcr.io/foo"}},
+	})
+	assert.Equal(t, headers{"Name"}, table.Headers)
+	assert.Equal(t, rows{{"foo", "gcr.io/foo"}}, table.Rows)
+}
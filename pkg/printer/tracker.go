@@ -0,0 +1,257 @@
+// Copyright 2020 TriggerMesh, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Deployment phases displayed by Tracker
+const (
+	PhaseQueued = "queued"
+	PhaseUpload = "upload"
+	PhaseBuild  = "build"
+	PhaseDeploy = "deploy"
+	PhaseReady  = "ready"
+	PhaseFailed = "failed"
+)
+
+const redrawInterval = 500 * time.Millisecond
+
+// Tracker renders live view of multiple deployments phases.
+// In terminal the view is redrawn in place, otherwise every phase change
+// is printed as a new line.
+type Tracker struct {
+	sync.Mutex
+	out   io.Writer
+	tty   bool
+	rows  []*Task
+	lines int
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// Task is a single tracked deployment
+type Task struct {
+	tracker *Tracker
+	Name    string
+	Phase   string
+	Result  string
+	Err     error
+	Started time.Time
+	// phases contain the time when each phase started
+	phases   map[string]time.Time
+	finished time.Time
+}
+
+// NewTracker returns Tracker that writes to provided output.
+// Interactive tracker redraws the view in place, so it should be used only with terminals.
+func NewTracker(out io.Writer, interactive bool) *Tracker {
+	return &Tracker{
+		out:  out,
+		tty:  interactive,
+		stop: make(chan struct{}),
+	}
+}
+
+// IsTerminal returns true if writer is a terminal
+func IsTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
+
+// Interactive returns true if tracker redraws the view in terminal
+func (t *Tracker) Interactive() bool {
+	return t.tty
+}
+
+// Add registers new task in tracker
+func (t *Tracker) Add(name string) *Task {
+	t.Lock()
+	defer t.Unlock()
+	task := &Task{
+		tracker: t,
+		Name:    name,
+		Phase:   PhaseQueued,
+		Started: time.Now(),
+		phases:  map[string]time.Time{},
+	}
+	t.rows = append(t.rows, task)
+	return task
+}
+
+// Start begins periodic view redraw
+func (t *Tracker) Start() {
+	if !t.tty {
+		return
+	}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(redrawInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.Lock()
+				t.redraw()
+				t.Unlock()
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop finishes view redraw
+func (t *Tracker) Stop() {
+	if t.tty {
+		close(t.stop)
+		t.wg.Wait()
+	}
+	t.Lock()
+	defer t.Unlock()
+	if t.tty {
+		t.redraw()
+	}
+}
+
+func (t *Tracker) redraw() {
+	if t.lines > 0 {
+		fmt.Fprintf(t.out, "\x1b[%dA", t.lines)
+	}
+	for _, task := range t.rows {
+		fmt.Fprintf(t.out, "\x1b[2K%s\n", task.line())
+	}
+	t.lines = len(t.rows)
+}
+
+// SetPhase updates current task phase
+func (task *Task) SetPhase(phase string) {
+	t := task.tracker
+	t.Lock()
+	defer t.Unlock()
+	if task.Phase == phase {
+		return
+	}
+	task.Phase = phase
+	task.phases[phase] = time.Now()
+	if !t.tty {
+		fmt.Fprintln(t.out, task.line())
+	}
+}
+
+// Done marks task as finished with provided result message or error
+func (task *Task) Done(result string, err error) {
+	t := task.tracker
+	t.Lock()
+	defer t.Unlock()
+	task.Result = result
+	task.Err = err
+	task.finished = time.Now()
+	task.Phase = PhaseReady
+	if err != nil {
+		task.Phase = PhaseFailed
+	}
+	if !t.tty {
+		fmt.Fprintln(t.out, task.line())
+	}
+}
+
+// Elapsed returns task duration
+func (task *Task) Elapsed() time.Duration {
+	if task.finished.IsZero() {
+		return time.Since(task.Started)
+	}
+	return task.finished.Sub(task.Started)
+}
+
+// PhaseDuration returns time spent between the beginning of the first of provided phases
+// and the beginning of the next phase
+func (task *Task) PhaseDuration(phases ...string) time.Duration {
+	var start time.Time
+	for _, phase := range phases {
+		if ts, ok := task.phases[phase]; ok && (start.IsZero() || ts.Before(start)) {
+			start = ts
+		}
+	}
+	if start.IsZero() {
+		return 0
+	}
+	end := task.finished
+	for phase, ts := range task.phases {
+		if contains(phases, phase) {
+			continue
+		}
+		if ts.After(start) && (end.IsZero() || ts.Before(end)) {
+			end = ts
+		}
+	}
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return end.Sub(start)
+}
+
+func (task *Task) line() string {
+	return fmt.Sprintf("%-40s %-8s %s", task.Name, task.Phase, task.Elapsed().Round(time.Second))
+}
+
+// Summary returns table with deployments results and durations
+func (t *Tracker) Summary() Table {
+	t.Lock()
+	defer t.Unlock()
+	table := Table{
+		Headers: []string{"Function", "Status", "Build", "Deploy", "Total", "Result"},
+		Rows:    make([][]string, 0, len(t.rows)),
+	}
+	for _, task := range t.rows {
+		result := task.Result
+		if task.Err != nil {
+			result = task.Err.Error()
+		}
+		table.Rows = append(table.Rows, []string{
+			task.Name,
+			task.Phase,
+			formatDuration(task.PhaseDuration(PhaseUpload, PhaseBuild)),
+			formatDuration(task.PhaseDuration(PhaseDeploy)),
+			formatDuration(task.Elapsed()),
+			result,
+		})
+	}
+	return table
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
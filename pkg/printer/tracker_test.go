@@ -0,0 +1,56 @@
+// Copyright 2020 TriggerMesh, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	tracker := NewTracker(buffer, false)
+	assert.False(t, tracker.Interactive())
+
+	foo := tracker.Add("foo")
+	bar := tracker.Add("bar")
+	tracker.Start()
+
+	foo.SetPhase(PhaseUpload)
+	foo.SetPhase(PhaseBuild)
+	foo.SetPhase(PhaseDeploy)
+	foo.Done("Service foo URL: http://foo.example.com", nil)
+	bar.SetPhase(PhaseBuild)
+	bar.Done("", errors.New("build failed"))
+	tracker.Stop()
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, 6)
+	assert.Contains(t, lines[0], PhaseUpload)
+	assert.Contains(t, lines[3], PhaseReady)
+	assert.Contains(t, lines[5], PhaseFailed)
+
+	summary := tracker.Summary()
+	assert.Len(t, summary.Rows, 2)
+	assert.Equal(t, []string{"foo", PhaseReady}, summary.Rows[0][:2])
+	assert.Equal(t, "Service foo URL: http://foo.example.com", summary.Rows[0][5])
+	assert.Equal(t, []string{"bar", PhaseFailed}, summary.Rows[1][:2])
+	assert.Equal(t, "-", summary.Rows[1][3])
+	assert.Equal(t, "build failed", summary.Rows[1][5])
+}
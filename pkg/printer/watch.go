@@ -0,0 +1,154 @@
+// Copyright 2020 TriggerMesh, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// ListWatcher prints list of k8s objects followed by the changes received
+// from the watch. In terminal the table is redrawn in place, otherwise
+// every changed object is printed as a new row.
+type ListWatcher struct {
+	printer *Printer
+	list    runtime.Object
+	table   func(list runtime.Object) Table
+	tty     bool
+	lines   int
+}
+
+// NewListWatcher returns ListWatcher for provided list. Table function
+// converts list of the same type into printable table
+func (p *Printer) NewListWatcher(list runtime.Object, table func(list runtime.Object) Table) *ListWatcher {
+	return &ListWatcher{
+		printer: p,
+		list:    list,
+		table:   table,
+		tty:     IsTerminal(p.Output),
+	}
+}
+
+// Print prints the whole list
+func (w *ListWatcher) Print() error {
+	if !w.tabular() {
+		if w.printer.Format == FormatJSON {
+			// separate list from the following objects
+			defer fmt.Fprintln(w.printer.Output)
+		}
+		return w.printer.PrintList(w.list, w.table(w.list))
+	}
+	return w.render(w.list, true)
+}
+
+// Update applies watch event to the list and prints the change
+func (w *ListWatcher) Update(event watch.Event) error {
+	switch event.Type {
+	case watch.Added, watch.Modified, watch.Deleted:
+	default:
+		return nil
+	}
+	if err := w.apply(event); err != nil {
+		return err
+	}
+	if !w.tabular() {
+		return w.printObject(event.Object)
+	}
+	if w.tty {
+		return w.render(w.list, true)
+	}
+	changed := w.list.DeepCopyObject()
+	if err := meta.SetList(changed, []runtime.Object{event.Object}); err != nil {
+		return err
+	}
+	return w.render(changed, false)
+}
+
+func (w *ListWatcher) tabular() bool {
+	format, _, err := parseFormat(w.printer.Format)
+	return err == nil && (format == "" || format == FormatWide)
+}
+
+// apply adds, replaces or removes event object in the list
+func (w *ListWatcher) apply(event watch.Event) error {
+	items, err := meta.ExtractList(w.list)
+	if err != nil {
+		return err
+	}
+	changed, err := meta.Accessor(event.Object)
+	if err != nil {
+		return err
+	}
+	result := make([]runtime.Object, 0, len(items)+1)
+	found := false
+	for _, item := range items {
+		accessor, err := meta.Accessor(item)
+		if err != nil {
+			return err
+		}
+		if accessor.GetNamespace() == changed.GetNamespace() && accessor.GetName() == changed.GetName() {
+			found = true
+			if event.Type == watch.Deleted {
+				continue
+			}
+			item = event.Object
+		}
+		result = append(result, item)
+	}
+	if !found && event.Type != watch.Deleted {
+		result = append(result, event.Object)
+	}
+	return meta.SetList(w.list, result)
+}
+
+// render prints list table, in terminal previously printed table is replaced
+func (w *ListWatcher) render(list runtime.Object, header bool) error {
+	var buf bytes.Buffer
+	p := *w.printer
+	p.Output = &buf
+	p.Table = newTableWriter(&buf)
+	if err := p.PrintList(list, w.table(list)); err != nil {
+		return err
+	}
+	out := buf.String()
+	if !header {
+		if i := strings.Index(out, "\n"); i != -1 {
+			out = out[i+1:]
+		}
+	}
+	if w.tty && w.lines > 0 {
+		// move cursor to the beginning of the previous table and clear the rest of the screen
+		fmt.Fprintf(w.printer.Output, "\x1b[%dA\x1b[J", w.lines)
+	}
+	fmt.Fprint(w.printer.Output, out)
+	w.lines = strings.Count(out, "\n")
+	return nil
+}
+
+// printObject prints changed object in one of the encoded formats
+func (w *ListWatcher) printObject(object runtime.Object) error {
+	switch w.printer.Format {
+	case FormatYAML:
+		fmt.Fprintln(w.printer.Output, "---")
+	case FormatJSON:
+		defer fmt.Fprintln(w.printer.Output)
+	}
+	return w.printer.PrintObject(Object{K8sObject: object})
+}
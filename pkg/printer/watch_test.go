@@ -0,0 +1,78 @@
+// Copyright 2020 TriggerMesh, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func TestListWatcher(t *testing.T) {
+	configMap := func(name, value string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Data:       map[string]string{"key": value},
+		}
+	}
+	table := func(list runtime.Object) Table {
+		table := Table{Headers: []string{"Name", "Key"}}
+		for _, item := range list.(*corev1.ConfigMapList).Items {
+			table.Rows = append(table.Rows, []string{item.Name, item.Data["key"]})
+		}
+		return table
+	}
+	events := []watch.Event{
+		{Type: watch.Modified, Object: configMap("foo", "2")},
+		{Type: watch.Added, Object: configMap("bar", "3")},
+		{Type: watch.Bookmark, Object: configMap("", "")},
+		{Type: watch.Deleted, Object: configMap("foo", "2")},
+	}
+
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   "",
+			expected: "NAME KEY\nfoo 1\nfoo 2\nbar 3\nfoo 2",
+		},
+		{
+			format:   "name",
+			expected: "configmap/foo\nconfigmap/foo\nconfigmap/bar\nconfigmap/foo",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			var out bytes.Buffer
+			p := NewPrinter(&out)
+			p.Format = tc.format
+			list := &corev1.ConfigMapList{Items: []corev1.ConfigMap{*configMap("foo", "1")}}
+			w := p.NewListWatcher(list, table)
+			assert.NoError(t, w.Print())
+			for _, event := range events {
+				assert.NoError(t, w.Update(event))
+			}
+			assert.Equal(t, tc.expected, normalize(out.String()))
+			assert.Len(t, list.Items, 1)
+			assert.Equal(t, "bar", list.Items[0].Name)
+		})
+	}
+}
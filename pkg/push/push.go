@@ -15,7 +15,6 @@
 package push
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
@@ -23,7 +22,6 @@ import (
 	tekton "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/triggermesh/tm/pkg/client"
 	"github.com/triggermesh/tm/pkg/file"
-	"github.com/triggermesh/tm/pkg/resources/pipelineresource"
 	"github.com/triggermesh/tm/pkg/resources/service"
 	"github.com/triggermesh/tm/pkg/resources/task"
 	"gopkg.in/src-d/go-git.v4"
@@ -35,8 +33,8 @@ import (
 )
 
 // Push tries to read git configuration in current directory and if it succeeds
-// tekton pipeline resource and task are being prepared to run "tm deploy" command.
-// Corresponding TaskRun object which binds these pipelineresources and tasks
+// tekton task is being prepared to clone repository and run "tm deploy" command.
+// Corresponding TaskRun object which binds the task with sources workspace
 // is printed to stdout.
 func Push(clientset *client.ConfigSet, token string) error {
 	repo, err := git.PlainOpen(".")
@@ -65,17 +63,6 @@ func Push(clientset *client.ConfigSet, token string) error {
 	project := parts[len(parts)-1]
 	owner := parts[len(parts)-2]
 
-	pipelineResourceObj := pipelineresource.PipelineResource{
-		Name:      project,
-		Namespace: client.Namespace,
-		Source: pipelineresource.Git{
-			URL: url,
-		},
-	}
-	if _, err := pipelineResourceObj.Deploy(clientset); err != nil {
-		return err
-	}
-
 	taskObj := task.Task{
 		Name:      project,
 		Namespace: client.Namespace,
@@ -85,7 +72,7 @@ func Push(clientset *client.ConfigSet, token string) error {
 		return err
 	}
 
-	taskrunObj := getTaskRun(project, client.Namespace)
+	taskrunObj := getTaskRun(project, client.Namespace, url)
 	taskrunYAML, err := yaml.Marshal(taskrunObj)
 	if err != nil {
 		return err
@@ -187,7 +174,7 @@ func getContainerSource(project, owner, token string) *sourcesv1.ContainerSource
 	}
 }
 
-func getTaskRun(taskName, namespace string) *tekton.TaskRun {
+func getTaskRun(taskName, namespace, url string) *tekton.TaskRun {
 	return &tekton.TaskRun{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "tekton.dev/v1beta1",
@@ -198,19 +185,21 @@ func getTaskRun(taskName, namespace string) *tekton.TaskRun {
 			Namespace:    namespace,
 		},
 		Spec: tekton.TaskRunSpec{
-			Resources: &tekton.TaskRunResources{
-				Inputs: []tekton.TaskResourceBinding{
-					{
-						PipelineResourceBinding: tekton.PipelineResourceBinding{
-							Name: "url",
-							ResourceRef: &tekton.PipelineResourceRef{
-								Name:       taskName,
-								APIVersion: "tekton.dev/v1beta1",
-							},
-						},
+			Params: []tekton.Param{
+				{
+					Name: task.GitURLParam,
+					Value: tekton.ArrayOrString{
+						Type:      tekton.ParamTypeString,
+						StringVal: url,
 					},
 				},
 			},
+			Workspaces: []tekton.WorkspaceBinding{
+				{
+					Name:     task.SourcesWorkspace,
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			},
 			TaskRef: &tekton.TaskRef{
 				Name:       taskName,
 				Kind:       "Task",
@@ -221,6 +210,7 @@ func getTaskRun(taskName, namespace string) *tekton.TaskRun {
 }
 
 func getTask(name, namespace string) *tekton.Task {
+	params, workspaces := task.GitSourceParams()
 	return &tekton.Task{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "tekton.dev/v1beta1",
@@ -231,23 +221,15 @@ func getTask(name, namespace string) *tekton.Task {
 			Namespace: namespace,
 		},
 		Spec: tekton.TaskSpec{
-			Resources: &tekton.TaskResources{
-				Inputs: []tekton.TaskResource{
-					{
-						ResourceDeclaration: tekton.ResourceDeclaration{
-							Name: "url",
-							Type: tekton.PipelineResourceTypeGit,
-						},
-					},
-				},
-			},
+			Params:     params,
+			Workspaces: workspaces,
 			Steps: []tekton.Step{
+				task.GitCloneStep(),
 				{
 					Name:    "deploy",
 					Image:   "gcr.io/triggermesh/tm",
 					Command: []string{"tm"},
-					// TODO: move sources from this illogical path; update pipelineresource/create.go
-					Args: []string{"deploy", "-f", "/workspace/url/", "--wait"},
+					Args:    []string{"deploy", "-f", fmt.Sprintf("$(workspaces.%s.path)", task.SourcesWorkspace), "--wait"},
 				},
 			},
 		},
@@ -255,7 +237,7 @@ func getTask(name, namespace string) *tekton.Task {
 }
 
 func createOrUpdateConfigmap(clientset *client.ConfigSet, cm *corev1.ConfigMap) error {
-	ctx := context.Background()
+	ctx := clientset.Context
 	_, err := clientset.Core.CoreV1().ConfigMaps(cm.Namespace).Create(ctx, cm, metav1.CreateOptions{})
 	if k8serrors.IsAlreadyExists(err) {
 		cmObj, err := clientset.Core.CoreV1().ConfigMaps(cm.Namespace).Get(ctx, cm.Name, metav1.GetOptions{})
@@ -273,7 +255,7 @@ func createOrUpdateConfigmap(clientset *client.ConfigSet, cm *corev1.ConfigMap)
 }
 
 func createOrUpdateContainersource(clientset *client.ConfigSet, cs *sourcesv1.ContainerSource) error {
-	ctx := context.Background()
+	ctx := clientset.Context
 	_, err := clientset.Eventing.SourcesV1().ContainerSources(cs.Namespace).Create(ctx, cs, metav1.CreateOptions{})
 	if k8serrors.IsAlreadyExists(err) {
 		csObj, err := clientset.Eventing.SourcesV1().ContainerSources(cs.Namespace).Get(ctx, cs.Name, metav1.GetOptions{})
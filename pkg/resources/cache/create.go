@@ -0,0 +1,86 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"fmt"
+
+	"github.com/triggermesh/tm/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// LabelKey marks objects created by tm to store build cache
+	LabelKey = "cli.triggermesh.io/cache"
+
+	defaultVolumeSize = "1Gi"
+)
+
+// CreateVolume creates persistent volume claim for the build cache if it does not exist yet
+func (c *Cache) CreateVolume(clientset *client.ConfigSet) error {
+	if c.Volume == "" || client.Dry {
+		return nil
+	}
+	ctx := clientset.Context
+	_, err := clientset.Core.CoreV1().PersistentVolumeClaims(c.Namespace).Get(ctx, c.Volume, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	if c.Size == "" {
+		c.Size = defaultVolumeSize
+	}
+	size, err := resource.ParseQuantity(c.Size)
+	if err != nil {
+		return fmt.Errorf("cache volume size %q: %s", c.Size, err)
+	}
+	accessMode := corev1.ReadWriteOnce
+	switch corev1.PersistentVolumeAccessMode(c.AccessMode) {
+	case "", corev1.ReadWriteOnce:
+	case corev1.ReadWriteMany:
+		accessMode = corev1.ReadWriteMany
+	default:
+		return fmt.Errorf("cache volume access mode %q is not supported, use %s or %s", c.AccessMode, corev1.ReadWriteOnce, corev1.ReadWriteMany)
+	}
+	clientset.Log.Debugf("creating cache volume claim \"%s/%s\" of %s", c.Namespace, c.Volume, c.Size)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.Volume,
+			Namespace: c.Namespace,
+			Labels: map[string]string{
+				LabelKey: "true",
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{accessMode},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		},
+	}
+	_, err = clientset.Core.CoreV1().PersistentVolumeClaims(c.Namespace).Create(ctx, pvc, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
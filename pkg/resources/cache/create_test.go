@@ -0,0 +1,71 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateVolume(t *testing.T) {
+	testCases := []struct {
+		name      string
+		cache     Cache
+		mode      corev1.PersistentVolumeAccessMode
+		exclusive bool
+		err       string
+	}{
+		{
+			name:      "default access mode",
+			cache:     Cache{Volume: "cache"},
+			mode:      corev1.ReadWriteOnce,
+			exclusive: true,
+		}, {
+			name:  "shared volume",
+			cache: Cache{Volume: "cache", AccessMode: "ReadWriteMany"},
+			mode:  corev1.ReadWriteMany,
+		}, {
+			name:      "unsupported access mode",
+			cache:     Cache{Volume: "cache", AccessMode: "ReadOnlyMany"},
+			exclusive: true,
+			err:       `cache volume access mode "ReadOnlyMany" is not supported, use ReadWriteOnce or ReadWriteMany`,
+		},
+	}
+
+	client.Dry = false
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewClient()
+			tc.cache.Namespace = "test-namespace"
+			assert.Equal(t, tc.exclusive, tc.cache.Exclusive())
+
+			err := tc.cache.CreateVolume(&clientset)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			pvc, err := clientset.Core.CoreV1().PersistentVolumeClaims("test-namespace").Get(context.Background(), "cache", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, []corev1.PersistentVolumeAccessMode{tc.mode}, pvc.Spec.AccessModes)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/triggermesh/tm/pkg/client"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// kaniko tags cached layers with hex encoded sha256 of the layer cache key
+var cacheTag = regexp.MustCompile("^[a-f0-9]{64}$")
+
+// Prune removes cached image layers from the registry repository and
+// deletes the cache volume claim. Volume is created again on the next build.
+// Prune returns the number of removed registry tags.
+func (c *Cache) Prune(clientset *client.ConfigSet) (int, error) {
+	var removed int
+	if c.Repository != "" {
+		var err error
+		if removed, err = c.pruneRepository(clientset); err != nil {
+			return removed, fmt.Errorf("pruning cache repository %q: %s", c.Repository, err)
+		}
+	}
+	if c.Volume != "" && !client.Dry {
+		clientset.Log.Debugf("removing cache volume claim \"%s/%s\"", c.Namespace, c.Volume)
+		err := clientset.Core.CoreV1().PersistentVolumeClaims(c.Namespace).Delete(clientset.Context, c.Volume, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return removed, fmt.Errorf("removing cache volume %q: %s", c.Volume, err)
+		}
+	}
+	return removed, nil
+}
+
+func (c *Cache) pruneRepository(clientset *client.ConfigSet) (int, error) {
+	var opts []name.Option
+	if clientset.Registry.SkipTLS {
+		opts = append(opts, name.Insecure)
+	}
+	repo, err := name.NewRepository(c.Repository, opts...)
+	if err != nil {
+		return 0, err
+	}
+	ropts := []remote.Option{
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithContext(clientset.Context),
+	}
+	tags, err := remote.List(repo, ropts...)
+	if err != nil {
+		return 0, err
+	}
+	var removed, skipped int
+	for _, tag := range tags {
+		// repository may be shared with application images,
+		// only layers cached by kaniko are removed
+		if !cacheTag.MatchString(tag) {
+			skipped++
+			continue
+		}
+		desc, err := remote.Head(repo.Tag(tag), ropts...)
+		if err != nil {
+			return removed, err
+		}
+		clientset.Log.Debugf("removing cached layer %s@%s", repo, desc.Digest)
+		if client.Dry {
+			removed++
+			continue
+		}
+		if err := remote.Delete(repo.Digest(desc.Digest.String()), ropts...); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	if skipped != 0 {
+		clientset.Log.Warnf("%d tags in %s are not build cache layers, skipping them", skipped, repo)
+	}
+	return removed, nil
+}
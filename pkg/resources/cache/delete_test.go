@@ -0,0 +1,65 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+)
+
+func TestPruneRepository(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+	defer server.Close()
+	host, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	repo, err := name.NewRepository(host.Host + "/foo/cache")
+	require.NoError(t, err)
+	push := func(tag string) string {
+		img, err := random.Image(64, 1)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(repo.Tag(tag), img))
+		digest, err := img.Digest()
+		require.NoError(t, err)
+		return digest.String()
+	}
+	layer := push(strings.Repeat("ab", 32))
+	app := push("v1")
+
+	client.Dry = false
+	clientset := fake.NewClient()
+	c := &Cache{Repository: repo.String()}
+	removed, err := c.Prune(&clientset)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	_, err = remote.Head(repo.Digest(layer))
+	assert.Error(t, err)
+	_, err = remote.Head(repo.Digest(app))
+	assert.NoError(t, err)
+}
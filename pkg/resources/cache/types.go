@@ -0,0 +1,41 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import corev1 "k8s.io/api/core/v1"
+
+// Cache describes where image build cache is stored between deployments.
+// Repository is the container registry repository for cached image layers,
+// Volume is the name of persistent volume claim mounted into build steps.
+type Cache struct {
+	Repository string
+	Volume     string
+	// Size of the volume claim created if Volume does not exist
+	Size string
+	// AccessMode of the created volume claim, ReadWriteOnce by default
+	AccessMode string
+	Namespace  string
+}
+
+// Enabled returns true if any cache storage is configured
+func (c Cache) Enabled() bool {
+	return c.Repository != "" || c.Volume != ""
+}
+
+// Exclusive returns true if cache volume may be mounted by one node at a time,
+// builds that use such volume cannot run in parallel
+func (c Cache) Exclusive() bool {
+	return c.Volume != "" && c.AccessMode != string(corev1.ReadWriteMany)
+}
@@ -19,7 +19,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	messagingapi "knative.dev/eventing/pkg/apis/messaging/v1"
 )
 
 func TestList(t *testing.T) {
@@ -28,13 +30,13 @@ func TestList(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	channelClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	channelClient := fake.NewClient(&messagingapi.InMemoryChannel{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: namespace}})
 
 	channel := &Channel{Namespace: namespace}
 
-	_, err = channel.List(&channelClient)
+	list, err := channel.List(&channelClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestDeploy(t *testing.T) {
@@ -43,8 +45,7 @@ func TestDeploy(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	channelClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	channelClient := fake.NewClient()
 
 	testCases := []struct {
 		Name        string
@@ -61,7 +62,7 @@ func TestDeploy(t *testing.T) {
 			Namespace: namespace,
 		}
 
-		err = channel.Deploy(&channelClient)
+		err := channel.Deploy(&channelClient)
 		if err != nil {
 			if tc.ExpectedErr != "" {
 				assert.EqualError(t, err, tc.ExpectedErr)
@@ -15,7 +15,6 @@
 package channel
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/ghodss/yaml"
@@ -36,6 +35,9 @@ func (c *Channel) Deploy(clientset *client.ConfigSet) error {
 		fmt.Printf("%s\n", res)
 		return nil
 	}
+	if err := clientset.Require(client.KnativeChannels); err != nil {
+		return err
+	}
 	return c.createOrUpdate(channelObject, clientset)
 }
 
@@ -49,14 +51,14 @@ func (c *Channel) newObject(clientset *client.ConfigSet) messagingapi.InMemoryCh
 }
 
 func (c *Channel) createOrUpdate(channelObject messagingapi.InMemoryChannel, clientset *client.ConfigSet) error {
-	_, err := clientset.Eventing.MessagingV1().InMemoryChannels(c.Namespace).Create(context.Background(), &channelObject, metav1.CreateOptions{})
+	_, err := clientset.Eventing.MessagingV1().InMemoryChannels(c.Namespace).Create(clientset.Context, &channelObject, metav1.CreateOptions{})
 	if k8serrors.IsAlreadyExists(err) {
-		channel, err := clientset.Eventing.MessagingV1().InMemoryChannels(c.Namespace).Get(context.Background(), channelObject.ObjectMeta.Name, metav1.GetOptions{})
+		channel, err := clientset.Eventing.MessagingV1().InMemoryChannels(c.Namespace).Get(clientset.Context, channelObject.ObjectMeta.Name, metav1.GetOptions{})
 		if err != nil {
 			return err
 		}
 		channelObject.ObjectMeta.ResourceVersion = channel.GetResourceVersion()
-		_, err = clientset.Eventing.MessagingV1().InMemoryChannels(c.Namespace).Update(context.Background(), &channelObject, metav1.UpdateOptions{})
+		_, err = clientset.Eventing.MessagingV1().InMemoryChannels(c.Namespace).Update(clientset.Context, &channelObject, metav1.UpdateOptions{})
 		return err
 	}
 	return err
@@ -15,8 +15,6 @@
 package channel
 
 import (
-	"context"
-
 	"github.com/triggermesh/tm/pkg/client"
 	"github.com/triggermesh/tm/pkg/printer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,5 +38,5 @@ func (c *Channel) GetObject(service *messagingapi.InMemoryChannel) printer.Objec
 
 // Get returns k8s object
 func (c *Channel) Get(clientset *client.ConfigSet) (*messagingapi.InMemoryChannel, error) {
-	return clientset.Eventing.MessagingV1().InMemoryChannels(c.Namespace).Get(context.Background(), c.Name, metav1.GetOptions{})
+	return clientset.Eventing.MessagingV1().InMemoryChannels(c.Namespace).Get(clientset.Context, c.Name, metav1.GetOptions{})
 }
@@ -15,7 +15,6 @@
 package channel
 
 import (
-	"context"
 	"fmt"
 	"time"
 
@@ -23,6 +22,7 @@ import (
 	"github.com/triggermesh/tm/pkg/printer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/watch"
 	messagingapi "knative.dev/eventing/pkg/apis/messaging/v1"
 )
 
@@ -72,5 +72,20 @@ func (c *Channel) row(item *messagingapi.InMemoryChannel) []string {
 
 // List returns list of knative build objects
 func (c *Channel) List(clientset *client.ConfigSet) (*messagingapi.InMemoryChannelList, error) {
-	return clientset.Eventing.MessagingV1().InMemoryChannels(c.Namespace).List(context.Background(), metav1.ListOptions{})
+	list := &messagingapi.InMemoryChannelList{}
+	err := client.ListPages(client.ListOptions(), func(opts metav1.ListOptions) (string, error) {
+		page, err := clientset.Eventing.MessagingV1().InMemoryChannels(c.Namespace).List(clientset.Context, opts)
+		if err != nil {
+			return "", err
+		}
+		list.Items = append(list.Items, page.Items...)
+		list.ResourceVersion = page.ResourceVersion
+		return page.Continue, nil
+	})
+	return list, err
+}
+
+// Watch opens watch of channels changes
+func (c *Channel) Watch(clientset *client.ConfigSet, opts metav1.ListOptions) (watch.Interface, error) {
+	return clientset.Eventing.MessagingV1().InMemoryChannels(c.Namespace).Watch(clientset.Context, opts)
 }
@@ -15,8 +15,6 @@
 package clustertask
 
 import (
-	"context"
-
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/triggermesh/tm/pkg/client"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,7 +22,7 @@ import (
 
 // Get returns tekton ClusterTask object by its name
 func (ct *ClusterTask) Get(clientset *client.ConfigSet) (*v1beta1.ClusterTask, error) {
-	return clientset.TektonTasks.TektonV1beta1().ClusterTasks().Get(context.Background(), ct.Name, metav1.GetOptions{})
+	return clientset.TektonTasks.TektonV1beta1().ClusterTasks().Get(clientset.Context, ct.Name, metav1.GetOptions{})
 }
 
 // Exist returns true if ClusterTask with provided name is available
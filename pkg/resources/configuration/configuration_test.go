@@ -19,7 +19,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
 func TestList(t *testing.T) {
@@ -28,13 +31,13 @@ func TestList(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	configurationClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	configurationClient := fake.NewClient(&servingv1.Configuration{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: namespace}})
 
 	config := &Configuration{Name: "Foo", Namespace: namespace}
 
-	_, err = config.List(&configurationClient)
+	list, err := config.List(&configurationClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
@@ -43,13 +46,11 @@ func TestGet(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	configurationClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	configurationClient := fake.NewClient()
 
 	config := &Configuration{Name: "Foo", Namespace: namespace}
-	result, err := config.Get(&configurationClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	_, err := config.Get(&configurationClient)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
 
 func TestDelete(t *testing.T) {
@@ -58,10 +59,9 @@ func TestDelete(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	configurationClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	configurationClient := fake.NewClient()
 
 	config := &Configuration{Name: "Foo", Namespace: namespace}
-	err = config.Delete(&configurationClient)
+	err := config.Delete(&configurationClient)
 	assert.Error(t, err)
 }
@@ -15,12 +15,10 @@
 package configuration
 
 import (
-	"context"
-
 	"github.com/triggermesh/tm/pkg/client"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func (c *Configuration) Delete(clientset *client.ConfigSet) error {
-	return clientset.Serving.ServingV1().Configurations(c.Namespace).Delete(context.Background(), c.Name, metav1.DeleteOptions{})
+	return clientset.Serving.ServingV1().Configurations(c.Namespace).Delete(clientset.Context, c.Name, metav1.DeleteOptions{})
 }
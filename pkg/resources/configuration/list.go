@@ -15,7 +15,6 @@
 package configuration
 
 import (
-	"context"
 	"fmt"
 	"time"
 
@@ -23,6 +22,7 @@ import (
 	"github.com/triggermesh/tm/pkg/printer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/watch"
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
@@ -75,5 +75,20 @@ func (cf *Configuration) row(item *servingv1.Configuration) []string {
 
 // List returns k8s list object
 func (cf *Configuration) List(clientset *client.ConfigSet) (*servingv1.ConfigurationList, error) {
-	return clientset.Serving.ServingV1().Configurations(cf.Namespace).List(context.Background(), metav1.ListOptions{})
+	list := &servingv1.ConfigurationList{}
+	err := client.ListPages(client.ListOptions(), func(opts metav1.ListOptions) (string, error) {
+		page, err := clientset.Serving.ServingV1().Configurations(cf.Namespace).List(clientset.Context, opts)
+		if err != nil {
+			return "", err
+		}
+		list.Items = append(list.Items, page.Items...)
+		list.ResourceVersion = page.ResourceVersion
+		return page.Continue, nil
+	})
+	return list, err
+}
+
+// Watch opens watch of configurations changes
+func (cf *Configuration) Watch(clientset *client.ConfigSet, opts metav1.ListOptions) (watch.Interface, error) {
+	return clientset.Serving.ServingV1().Configurations(cf.Namespace).Watch(clientset.Context, opts)
 }
@@ -0,0 +1,181 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credential
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGitHost(t *testing.T) {
+	testCases := []struct {
+		url  string
+		user string
+		host string
+		ssh  bool
+	}{
+		{"https://github.com/triggermesh/tm.git", "", "github.com", false},
+		{"http://git.example.com:8080/foo/bar", "", "git.example.com:8080", false},
+		{"git@github.com:triggermesh/tm.git", "git", "github.com", true},
+		{"ssh://gitlab.com/foo/bar.git", "git", "gitlab.com", true},
+		{"ssh://deploy@gitlab.com:2222/foo/bar.git", "deploy", "gitlab.com:2222", true},
+		{"./local/path", "", "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			user, host, ssh := gitHost(tc.url)
+			assert.Equal(t, tc.user, user)
+			assert.Equal(t, tc.host, host)
+			assert.Equal(t, tc.ssh, ssh)
+		})
+	}
+}
+
+func TestGitSecret(t *testing.T) {
+	testCases := []struct {
+		name       string
+		creds      GitCreds
+		secretName string
+		secretType corev1.SecretType
+		annotation string
+	}{
+		{
+			name:       "token",
+			creds:      GitCreds{Host: "github.com", Token: "foo"},
+			secretName: "git-token-github-com",
+			secretType: corev1.SecretTypeBasicAuth,
+			annotation: "https://github.com",
+		},
+		{
+			name:       "ssh key",
+			creds:      GitCreds{Host: "git.example.com:2222", Key: "bar"},
+			secretName: "git-ssh-git-example-com-2222",
+			secretType: corev1.SecretTypeSSHAuth,
+			annotation: "git.example.com:2222",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			secret := tc.creds.secret()
+			assert.Equal(t, tc.secretName, secret.Name)
+			assert.Equal(t, tc.secretType, secret.Type)
+			assert.Equal(t, tc.annotation, secret.Annotations[gitAnnotationKey])
+			assert.Equal(t, gitLabel, secret.Labels[LabelKey])
+		})
+	}
+	secret := (&GitCreds{Host: "github.com", Token: "foo"}).secret()
+	assert.Equal(t, defaultGitUsername, secret.StringData[corev1.BasicAuthUsernameKey])
+	assert.Equal(t, "foo", secret.StringData[corev1.BasicAuthPasswordKey])
+}
+
+func TestCredentialsRow(t *testing.T) {
+	accounts := []corev1.ServiceAccount{
+		{
+			ObjectMeta:       metav1.ObjectMeta{Name: "default"},
+			Secrets:          []corev1.ObjectReference{{Name: "git-token-github-com"}},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registries"}},
+		},
+		{
+			ObjectMeta:       metav1.ObjectMeta{Name: "builder"},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registries"}},
+		},
+	}
+	testCases := []struct {
+		name     string
+		secret   corev1.Secret
+		expected []string
+	}{
+		{
+			name:     "git token",
+			secret:   (&GitCreds{Host: "github.com", Token: "foo"}).secret(),
+			expected: []string{"git-token-github-com", "git-token", "github.com", "clone", "default"},
+		},
+		{
+			name:     "git ssh",
+			secret:   (&GitCreds{Host: "gitlab.com", Key: "foo"}).secret(),
+			expected: []string{"git-ssh-gitlab-com", "git-ssh", "gitlab.com", "clone", ""},
+		},
+		{
+			name: "registry",
+			secret: corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "registries"},
+				Type:       corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					pullConfigKey: []byte(`{"auths":{"gcr.io":{},"https://index.docker.io/v1/":{}}}`),
+					pushConfigKey: []byte(`{"auths":{"gcr.io":{}}}`),
+				},
+			},
+			expected: []string{"registries", "registry", "docker.io,gcr.io", "pull,push", "default,builder"},
+		},
+		{
+			name: "pull only registry",
+			secret: corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "pull"},
+				Type:       corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					pullConfigKey: []byte(`{"auths":{"quay.io":{}}}`),
+				},
+			},
+			expected: []string{"pull", "registry", "quay.io", "pull", ""},
+		},
+	}
+	c := Credentials{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			row := c.row(&tc.secret, accounts)
+			// age column is not compared
+			assert.Equal(t, tc.expected, row[:len(row)-1])
+		})
+	}
+}
+
+func TestUnlinkSecret(t *testing.T) {
+	sa := corev1.ServiceAccount{
+		Secrets:          []corev1.ObjectReference{{Name: "foo"}, {Name: "bar"}},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "bar"}, {Name: "baz"}},
+	}
+	assert.True(t, unlinkSecret(&sa, "bar"))
+	assert.Equal(t, []corev1.ObjectReference{{Name: "foo"}}, sa.Secrets)
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "baz"}}, sa.ImagePullSecrets)
+	assert.False(t, unlinkSecret(&sa, "bar"))
+}
+
+func TestCreateRegistryCredsEmptySecret(t *testing.T) {
+	client.Dry = false
+	clientset := fake.NewClient(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "test-namespace"}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "test-namespace"}},
+	)
+
+	c := &RegistryCreds{
+		Name:      "registry",
+		Namespace: "test-namespace",
+		Host:      "registry.example.com",
+		Username:  "user",
+		Password:  "secret",
+	}
+	assert.NoError(t, c.CreateRegistryCreds(&clientset))
+
+	secret, err := clientset.Core.CoreV1().Secrets("test-namespace").Get(context.Background(), "registry", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, string(secret.Data[pullConfigKey]), "registry.example.com")
+	assert.Contains(t, string(secret.Data[pushConfigKey]), "registry.example.com")
+}
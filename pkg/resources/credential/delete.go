@@ -0,0 +1,47 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credential
+
+import (
+	"fmt"
+
+	"github.com/triggermesh/tm/pkg/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Delete removes credentials secret and its references from all ServiceAccounts in the namespace
+func (c *Credentials) Delete(clientset *client.ConfigSet) error {
+	ctx := clientset.Context
+	secret, err := clientset.Core.CoreV1().Secrets(c.Namespace).Get(ctx, c.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if _, ok := secret.Labels[LabelKey]; !ok {
+		return fmt.Errorf("secret %q is not created by tm", c.Name)
+	}
+	accounts, err := c.ServiceAccounts(clientset)
+	if err != nil {
+		return err
+	}
+	for i := range accounts {
+		if !unlinkSecret(&accounts[i], c.Name) {
+			continue
+		}
+		if _, err := clientset.Core.CoreV1().ServiceAccounts(c.Namespace).Update(ctx, &accounts[i], metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("unlinking from service account %q: %s", accounts[i].Name, err)
+		}
+	}
+	return clientset.Core.CoreV1().Secrets(c.Namespace).Delete(ctx, c.Name, metav1.DeleteOptions{})
+}
@@ -0,0 +1,196 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credential
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+)
+
+// dockerConfig is the subset of docker config.json format used in registry secrets
+type dockerConfig struct {
+	Project     string                `json:"project,omitempty"`
+	Auths       map[string]dockerAuth `json:"auths"`
+	CredHelpers map[string]string     `json:"credHelpers,omitempty"`
+	CredsStore  string                `json:"credsStore,omitempty"`
+}
+
+type dockerAuth struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// helperCredentials is the output of docker credential helper "get" command
+type helperCredentials struct {
+	Username string
+	Secret   string
+}
+
+// DockerConfigPath returns the default path of the local docker config
+func DockerConfigPath() string {
+	if dir, ok := os.LookupEnv("DOCKER_CONFIG"); ok {
+		return path.Join(dir, "config.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return path.Join(home, ".docker", "config.json")
+}
+
+func parseDockerConfig(data []byte) (dockerConfig, error) {
+	config := dockerConfig{}
+	if len(data) != 0 {
+		if err := json.Unmarshal(data, &config); err != nil {
+			return config, err
+		}
+	}
+	if config.Auths == nil {
+		config.Auths = make(map[string]dockerAuth)
+	}
+	return config, nil
+}
+
+// readDockerConfig reads the local docker config and resolves credentials of the registries,
+// including ones stored by credential helpers. If hosts are set, only these registries are returned.
+func readDockerConfig(file string, hosts ...string) (dockerConfig, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return dockerConfig{}, err
+	}
+	local, err := parseDockerConfig(data)
+	if err != nil {
+		return dockerConfig{}, fmt.Errorf("parsing %s: %s", file, err)
+	}
+	helpers := make(map[string]string, len(local.CredHelpers)+len(local.Auths))
+	for host := range local.Auths {
+		if local.CredsStore != "" {
+			helpers[host] = local.CredsStore
+		}
+	}
+	for host, helper := range local.CredHelpers {
+		helpers[host] = helper
+	}
+
+	result := dockerConfig{Auths: make(map[string]dockerAuth)}
+	for host, auth := range local.Auths {
+		if auth.Username != "" || auth.Auth != "" {
+			result.Auths[host] = auth
+		}
+	}
+	for host, helper := range helpers {
+		if _, ok := result.Auths[host]; ok || !selected(host, hosts) {
+			continue
+		}
+		creds, err := helperGet(helper, host)
+		if err != nil {
+			return dockerConfig{}, fmt.Errorf("credential helper %q for %s: %s", helper, host, err)
+		}
+		result.Auths[host] = dockerAuth{
+			Username: creds.Username,
+			Password: creds.Secret,
+		}
+	}
+	for host := range result.Auths {
+		if !selected(host, hosts) {
+			delete(result.Auths, host)
+		}
+	}
+	if len(result.Auths) == 0 {
+		return result, fmt.Errorf("no registry credentials found in %s", file)
+	}
+	return result, nil
+}
+
+func selected(host string, hosts []string) bool {
+	if len(hosts) == 0 {
+		return true
+	}
+	for _, h := range hosts {
+		if RegistryHost(h) == RegistryHost(host) {
+			return true
+		}
+	}
+	return false
+}
+
+// helperGet runs docker-credential-<helper> to get the registry credentials
+func helperGet(helper, host string) (helperCredentials, error) {
+	var creds helperCredentials
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(host)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return creds, fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()+stdout.String()))
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return creds, err
+	}
+	return creds, nil
+}
+
+// merge adds registries from the other config replacing existing entries of the same hosts
+func (c *dockerConfig) merge(other dockerConfig) {
+	for host, auth := range other.Auths {
+		for existing := range c.Auths {
+			if RegistryHost(existing) == RegistryHost(host) {
+				delete(c.Auths, existing)
+			}
+		}
+		if auth.Auth == "" {
+			auth.Auth = base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		}
+		c.Auths[host] = auth
+	}
+	if other.Project != "" {
+		c.Project = other.Project
+	}
+}
+
+func (c dockerConfig) hosts() []string {
+	hosts := make([]string, 0, len(c.Auths))
+	for host := range c.Auths {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// RegistryHost returns registry address without scheme and path
+// with Docker Hub aliases replaced by "docker.io"
+func RegistryHost(address string) string {
+	if i := strings.Index(address, "://"); i != -1 {
+		address = address[i+3:]
+	}
+	if i := strings.Index(address, "/"); i != -1 {
+		address = address[:i]
+	}
+	switch address {
+	case "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+	return address
+}
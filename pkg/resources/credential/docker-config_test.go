@@ -0,0 +1,86 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credential
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadDockerConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tm-docker-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	helper := "#!/bin/sh\nread host\necho \"{\\\"ServerURL\\\":\\\"$host\\\",\\\"Username\\\":\\\"helper-user\\\",\\\"Secret\\\":\\\"helper-secret\\\"}\"\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "docker-credential-fake"), []byte(helper), 0755))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	config := `{
+  "auths": {
+    "https://index.docker.io/v1/": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("foo:bar")) + `"},
+    "gcr.io": {}
+  },
+  "credHelpers": {
+    "gcr.io": "fake"
+  }
+}`
+	path := filepath.Join(dir, "config.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(config), 0644))
+
+	result, err := readDockerConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gcr.io", "https://index.docker.io/v1/"}, result.hosts())
+	assert.Equal(t, dockerAuth{Username: "helper-user", Password: "helper-secret"}, result.Auths["gcr.io"])
+
+	result, err = readDockerConfig(path, "docker.io")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://index.docker.io/v1/"}, result.hosts())
+
+	_, err = readDockerConfig(path, "quay.io")
+	assert.Error(t, err)
+}
+
+func TestDockerConfigMerge(t *testing.T) {
+	config, err := parseDockerConfig([]byte(`{"auths":{"https://index.docker.io/v1/":{"username":"old"},"quay.io":{"username":"foo"}}}`))
+	assert.NoError(t, err)
+	config.merge(dockerConfig{
+		Project: "bar",
+		Auths: map[string]dockerAuth{
+			"docker.io": {Username: "new", Password: "secret"},
+		},
+	})
+	assert.Equal(t, "bar", config.Project)
+	assert.Equal(t, []string{"docker.io", "quay.io"}, config.hosts())
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("new:secret")), config.Auths["docker.io"].Auth)
+}
+
+func TestRegistryHost(t *testing.T) {
+	testCases := map[string]string{
+		"https://index.docker.io/v1/": "docker.io",
+		"registry-1.docker.io":        "docker.io",
+		"gcr.io":                      "gcr.io",
+		"https://eu.gcr.io":           "eu.gcr.io",
+		"localhost:5000/v2/":          "localhost:5000",
+	}
+	for address, expected := range testCases {
+		assert.Equal(t, expected, RegistryHost(address), address)
+	}
+}
@@ -16,70 +16,171 @@ package credential
 
 import (
 	"bufio"
-	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
+	"strings"
 
 	"github.com/triggermesh/tm/pkg/client"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	gitssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var secretName = "git-ssh-key"
+const (
+	// tekton creds-init reads git credentials for the host from this annotation
+	gitAnnotationKey   = "tekton.dev/git-0"
+	defaultGitUsername = "git"
+)
+
+var nonDNSChars = regexp.MustCompile("[^a-z0-9-]+")
 
+// CreateGitCreds creates or updates the secret with the git host credentials
+// and links it to the ServiceAccount so that build TaskRuns can clone private repositories
 func (g *GitCreds) CreateGitCreds(clientset *client.ConfigSet) error {
-	if len(g.Key) == 0 {
+	g.Host = normalizeHost(g.Host)
+	if g.Host == "" {
+		return fmt.Errorf("git host cannot be empty")
+	}
+	if g.Token != "" && g.Key != "" {
+		return fmt.Errorf("token and SSH key cannot be used together")
+	}
+	if g.Token == "" && g.Key == "" {
 		g.readStdin()
 	}
-	secretData := map[string]string{
-		"ssh-privatekey": g.Key,
+	secret := g.secret()
+	ctx := clientset.Context
+	_, err := clientset.Core.CoreV1().Secrets(g.Namespace).Create(ctx, &secret, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		oldSecret, err := clientset.Core.CoreV1().Secrets(g.Namespace).Get(ctx, secret.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if oldSecret.Labels == nil {
+			oldSecret.Labels = make(map[string]string)
+		}
+		oldSecret.Labels[LabelKey] = gitLabel
+		oldSecret.Annotations = secret.Annotations
+		oldSecret.Data = nil
+		oldSecret.StringData = secret.StringData
+		if _, err := clientset.Core.CoreV1().Secrets(g.Namespace).Update(ctx, oldSecret, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
 	}
+	return linkSecret(clientset, g.Namespace, g.ServiceAccount, secret.Name, false)
+}
+
+// secret returns basic-auth secret if token is set, ssh-auth secret otherwise
+func (g *GitCreds) secret() corev1.Secret {
 	secret := corev1.Secret{
-		Type: "kubernetes.io/ssh-auth",
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Secret",
 			APIVersion: "v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
 			Namespace: g.Namespace,
-			Annotations: map[string]string{
-				"build.knative.dev/git-0": "*",
+			Labels: map[string]string{
+				LabelKey: gitLabel,
 			},
 		},
-		StringData: secretData,
 	}
-	ctx := context.Background()
-	_, err := clientset.Core.CoreV1().Secrets(client.Namespace).Create(ctx, &secret, metav1.CreateOptions{})
-	if k8serrors.IsAlreadyExists(err) {
-		oldSecret, err := clientset.Core.CoreV1().Secrets(client.Namespace).Get(ctx, secretName, metav1.GetOptions{})
-		if err != nil {
-			return err
+	hostName := strings.Trim(nonDNSChars.ReplaceAllString(strings.ToLower(g.Host), "-"), "-")
+	if g.Token != "" {
+		username := g.Username
+		if username == "" {
+			username = defaultGitUsername
 		}
-		oldSecret.StringData = secretData
-		if _, err := clientset.Core.CoreV1().Secrets(client.Namespace).Update(ctx, oldSecret, metav1.UpdateOptions{}); err != nil {
-			return err
+		secret.Name = "git-token-" + hostName
+		secret.Type = corev1.SecretTypeBasicAuth
+		secret.Annotations = map[string]string{gitAnnotationKey: "https://" + g.Host}
+		secret.StringData = map[string]string{
+			corev1.BasicAuthUsernameKey: username,
+			corev1.BasicAuthPasswordKey: g.Token,
 		}
+		return secret
+	}
+	secret.Name = "git-ssh-" + hostName
+	secret.Type = corev1.SecretTypeSSHAuth
+	secret.Annotations = map[string]string{gitAnnotationKey: g.Host}
+	secret.StringData = map[string]string{
+		corev1.SSHAuthPrivateKey: g.Key,
+	}
+	return secret
+}
+
+// GitAuth returns authentication method for the repository URL based on the secrets
+// created by "tm set git-auth". Nil is returned if there are no credentials for the repository host.
+func GitAuth(clientset *client.ConfigSet, namespace, repository string) (transport.AuthMethod, error) {
+	user, host, ssh := gitHost(repository)
+	if host == "" {
+		return nil, nil
+	}
+	secrets, err := clientset.Core.CoreV1().Secrets(namespace).List(clientset.Context, metav1.ListOptions{
+		LabelSelector: LabelKey + "=" + gitLabel,
+	})
+	if k8serrors.IsForbidden(err) {
+		// user may clone public repositories without access to secrets
+		return nil, nil
 	} else if err != nil {
-		return err
+		return nil, err
 	}
-	sa, err := clientset.Core.CoreV1().ServiceAccounts(client.Namespace).Get(ctx, "default", metav1.GetOptions{})
-	if err != nil {
-		return err
+	for _, secret := range secrets.Items {
+		if normalizeHost(secret.Annotations[gitAnnotationKey]) != host {
+			continue
+		}
+		switch {
+		case secret.Type == corev1.SecretTypeBasicAuth && !ssh:
+			return &http.BasicAuth{
+				Username: string(secret.Data[corev1.BasicAuthUsernameKey]),
+				Password: string(secret.Data[corev1.BasicAuthPasswordKey]),
+			}, nil
+		case secret.Type == corev1.SecretTypeSSHAuth && ssh:
+			auth, err := gitssh.NewPublicKeys(user, secret.Data[corev1.SSHAuthPrivateKey], "")
+			if err != nil {
+				return nil, fmt.Errorf("secret %q: %s", secret.Name, err)
+			}
+			return auth, nil
+		}
 	}
+	return nil, nil
+}
 
-	for _, v := range sa.Secrets {
-		if v.Name == secretName {
-			return nil
+// gitHost returns user and host of the repository URL and true if URL uses SSH protocol
+func gitHost(repository string) (string, string, bool) {
+	// scp-like syntax, e.g. git@github.com:triggermesh/tm.git
+	if !strings.Contains(repository, "://") {
+		at := strings.Index(repository, "@")
+		colon := strings.Index(repository, ":")
+		if at == -1 || colon < at {
+			return "", "", false
 		}
+		return repository[:at], repository[at+1 : colon], true
 	}
-	sa.Secrets = append(sa.Secrets, corev1.ObjectReference{
-		Name:      secretName,
-		Namespace: client.Namespace,
-	})
-	_, err = clientset.Core.CoreV1().ServiceAccounts(client.Namespace).Update(ctx, sa, metav1.UpdateOptions{})
-	return err
+	u, err := url.Parse(repository)
+	if err != nil {
+		return "", "", false
+	}
+	if u.Scheme != "ssh" {
+		return "", u.Host, false
+	}
+	user := u.User.Username()
+	if user == "" {
+		user = defaultGitUsername
+	}
+	return user, u.Host, true
+}
+
+func normalizeHost(host string) string {
+	for _, scheme := range []string{"https://", "http://", "ssh://"} {
+		host = strings.TrimPrefix(host, scheme)
+	}
+	return strings.TrimSuffix(host, "/")
 }
 
 func (g *GitCreds) readStdin() {
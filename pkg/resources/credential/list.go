@@ -0,0 +1,142 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credential
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// List returns secrets with credentials created by tm
+func (c *Credentials) List(clientset *client.ConfigSet) (*corev1.SecretList, error) {
+	list := &corev1.SecretList{}
+	err := client.ListPages(withLabel(client.ListOptions()), func(opts metav1.ListOptions) (string, error) {
+		page, err := clientset.Core.CoreV1().Secrets(c.Namespace).List(clientset.Context, opts)
+		if err != nil {
+			return "", err
+		}
+		list.Items = append(list.Items, page.Items...)
+		list.ResourceVersion = page.ResourceVersion
+		return page.Continue, nil
+	})
+	return list, err
+}
+
+// Watch opens watch of credentials secrets changes
+func (c *Credentials) Watch(clientset *client.ConfigSet, opts metav1.ListOptions) (watch.Interface, error) {
+	return clientset.Core.CoreV1().Secrets(c.Namespace).Watch(clientset.Context, withLabel(opts))
+}
+
+// withLabel limits selected secrets to the ones created by tm
+func withLabel(opts metav1.ListOptions) metav1.ListOptions {
+	if opts.LabelSelector == "" {
+		opts.LabelSelector = LabelKey
+	} else {
+		opts.LabelSelector = LabelKey + "," + opts.LabelSelector
+	}
+	return opts
+}
+
+// ServiceAccounts returns ServiceAccounts in the namespace to show credentials usage
+func (c *Credentials) ServiceAccounts(clientset *client.ConfigSet) ([]corev1.ServiceAccount, error) {
+	list, err := clientset.Core.CoreV1().ServiceAccounts(c.Namespace).List(clientset.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// GetTable converts credentials secrets list into printable table.
+// Secret values are never printed.
+func (c *Credentials) GetTable(list *corev1.SecretList, accounts []corev1.ServiceAccount) printer.Table {
+	table := printer.Table{
+		Headers: []string{
+			"Name",
+			"Type",
+			"Hosts",
+			"Access",
+			"Service Accounts",
+			"Age",
+		},
+		Rows: make([][]string, 0, len(list.Items)),
+	}
+	for _, item := range list.Items {
+		table.Rows = append(table.Rows, c.row(&item, accounts))
+	}
+	return table
+}
+
+func (c *Credentials) row(item *corev1.Secret, accounts []corev1.ServiceAccount) []string {
+	var kind, hosts, access string
+	switch item.Type {
+	case corev1.SecretTypeBasicAuth:
+		kind, access = "git-token", "clone"
+		hosts = normalizeHost(item.Annotations[gitAnnotationKey])
+	case corev1.SecretTypeSSHAuth:
+		kind, access = "git-ssh", "clone"
+		hosts = normalizeHost(item.Annotations[gitAnnotationKey])
+	default:
+		kind = registryLabel
+		hosts, access = registryAccess(item)
+	}
+	var linked []string
+	for i := range accounts {
+		if hasSecret(&accounts[i], item.Name) || hasPullSecret(&accounts[i], item.Name) {
+			linked = append(linked, accounts[i].Name)
+		}
+	}
+	return []string{
+		item.Name,
+		kind,
+		hosts,
+		access,
+		strings.Join(linked, ","),
+		duration.HumanDuration(time.Since(item.GetCreationTimestamp().Time)),
+	}
+}
+
+// registryAccess returns comma-separated registry hosts and push/pull access of the registry secret
+func registryAccess(secret *corev1.Secret) (string, string) {
+	unique := make(map[string]bool)
+	var access []string
+	for _, key := range []string{pullConfigKey, pushConfigKey} {
+		config, err := parseDockerConfig(secret.Data[key])
+		if err != nil || len(config.Auths) == 0 {
+			continue
+		}
+		for host := range config.Auths {
+			unique[RegistryHost(host)] = true
+		}
+		if key == pullConfigKey {
+			access = append(access, "pull")
+		} else {
+			access = append(access, "push")
+		}
+	}
+	hosts := make([]string, 0, len(unique))
+	for host := range unique {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return strings.Join(hosts, ","), strings.Join(access, ",")
+}
@@ -16,7 +16,7 @@ package credential
 
 import (
 	"bufio"
-	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -24,68 +24,118 @@ import (
 	"github.com/triggermesh/tm/pkg/client"
 	"golang.org/x/crypto/ssh/terminal"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// SetRegistryCreds creates Secret with docker registry credentials json which later can be mounted as config.json file
+const (
+	pushConfigKey = "config.json"
+	pullConfigKey = ".dockerconfigjson"
+)
+
+// CreateRegistryCreds creates Secret with docker registry credentials json which later can be mounted as config.json file.
+// If the secret already exists, new registry is added to it, so that one secret may hold credentials for several registries.
 func (c *RegistryCreds) CreateRegistryCreds(clientset *client.ConfigSet) error {
-	secrets := make(map[string]string)
-	if !gitlabCI() && (len(c.Password) == 0 || len(c.Host) == 0 || len(c.Username) == 0) {
-		if err := c.readStdin(); err != nil {
-			return err
-		}
+	config, err := c.config()
+	if err != nil {
+		return err
+	}
+	ctx := clientset.Context
+	secrets := map[string][]byte{}
+	existing, err := clientset.Core.CoreV1().Secrets(c.Namespace).Get(ctx, c.Name, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
 	}
-	secret := fmt.Sprintf("{\"project\":%q,\"auths\":{%q:{\"username\":%q,\"password\":%q}}}", c.ProjectID, c.Host, c.Username, c.Password)
-	ctx := context.Background()
-	if s, err := clientset.Core.CoreV1().Secrets(c.Namespace).Get(ctx, c.Name, metav1.GetOptions{}); err == nil {
-		for k, v := range s.Data {
-			secrets[k] = string(v)
+	found := err == nil
+	if found && existing.Data != nil {
+		secrets = existing.Data
+	}
+
+	pull := c.Pull || c.Pull == c.Push
+	push := c.Push || c.Push == c.Pull
+	for key, enabled := range map[string]bool{pullConfigKey: pull, pushConfigKey: push} {
+		if !enabled {
+			continue
 		}
-		if err = clientset.Core.CoreV1().Secrets(c.Namespace).Delete(ctx, c.Name, metav1.DeleteOptions{}); err != nil {
+		current, err := parseDockerConfig(secrets[key])
+		if err != nil {
+			return fmt.Errorf("secret %q key %q: %s", c.Name, key, err)
+		}
+		current.merge(config)
+		if secrets[key], err = json.Marshal(current); err != nil {
 			return err
 		}
 	}
-
-	if c.Pull || c.Pull == c.Push {
-		secrets[".dockerconfigjson"] = secret
-	}
-	if c.Push || c.Push == c.Pull {
-		secrets["config.json"] = secret
-	}
-	if _, ok := secrets[".dockerconfigjson"]; !ok {
-		secrets[".dockerconfigjson"] = "{}"
-	}
-	newSecret := corev1.Secret{
-		Type: "kubernetes.io/dockerconfigjson",
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Secret",
-			APIVersion: "v1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      c.Name,
-			Namespace: client.Namespace,
-		},
-		StringData: secrets,
-	}
-	if _, err := clientset.Core.CoreV1().Secrets(client.Namespace).Create(ctx, &newSecret, metav1.CreateOptions{}); err != nil {
-		return err
+	if _, ok := secrets[pullConfigKey]; !ok {
+		secrets[pullConfigKey] = []byte("{}")
 	}
 
-	if c.Pull || c.Pull == c.Push {
-		sa, err := clientset.Core.CoreV1().ServiceAccounts(client.Namespace).Get(ctx, "default", metav1.GetOptions{})
-		if err != nil {
+	if found {
+		if existing.Labels == nil {
+			existing.Labels = make(map[string]string)
+		}
+		existing.Labels[LabelKey] = registryLabel
+		existing.Data = secrets
+		if _, err := clientset.Core.CoreV1().Secrets(c.Namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
 			return err
 		}
-		sa.ImagePullSecrets = []corev1.LocalObjectReference{
-			{Name: c.Name},
+	} else {
+		newSecret := corev1.Secret{
+			Type: corev1.SecretTypeDockerConfigJson,
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Secret",
+				APIVersion: "v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.Name,
+				Namespace: c.Namespace,
+				Labels: map[string]string{
+					LabelKey: registryLabel,
+				},
+			},
+			Data: secrets,
 		}
-		if _, err := clientset.Core.CoreV1().ServiceAccounts(client.Namespace).Update(ctx, sa, metav1.UpdateOptions{}); err != nil {
+		if _, err := clientset.Core.CoreV1().Secrets(c.Namespace).Create(ctx, &newSecret, metav1.CreateOptions{}); err != nil {
 			return err
 		}
 	}
+
+	if pull {
+		return linkSecret(clientset, c.Namespace, c.ServiceAccount, c.Name, true)
+	}
 	return nil
 }
 
+// config returns registry credentials either from the local docker config or from the command arguments
+func (c *RegistryCreds) config() (dockerConfig, error) {
+	if c.DockerConfig != "" {
+		var hosts []string
+		if c.Host != "" {
+			hosts = append(hosts, c.Host)
+		}
+		config, err := readDockerConfig(c.DockerConfig, hosts...)
+		if err != nil {
+			return config, err
+		}
+		config.Project = c.ProjectID
+		return config, nil
+	}
+	if !gitlabCI() && (len(c.Password) == 0 || len(c.Host) == 0 || len(c.Username) == 0) {
+		if err := c.readStdin(); err != nil {
+			return dockerConfig{}, err
+		}
+	}
+	return dockerConfig{
+		Project: c.ProjectID,
+		Auths: map[string]dockerAuth{
+			c.Host: {
+				Username: c.Username,
+				Password: c.Password,
+			},
+		},
+	}, nil
+}
+
 func (c *RegistryCreds) readStdin() error {
 	reader := bufio.NewReader(os.Stdin)
 	if len(c.Host) == 0 {
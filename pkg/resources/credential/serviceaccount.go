@@ -0,0 +1,96 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credential
+
+import (
+	"github.com/triggermesh/tm/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// linkSecret adds the secret to the ServiceAccount secrets and, if pull is set, image pull secrets.
+// Existing references are preserved.
+func linkSecret(clientset *client.ConfigSet, namespace, serviceAccount, name string, pull bool) error {
+	if serviceAccount == "" {
+		serviceAccount = defaultServiceAccount
+	}
+	ctx := clientset.Context
+	sa, err := clientset.Core.CoreV1().ServiceAccounts(namespace).Get(ctx, serviceAccount, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	changed := false
+	if !pull && !hasSecret(sa, name) {
+		sa.Secrets = append(sa.Secrets, corev1.ObjectReference{
+			Name:      name,
+			Namespace: namespace,
+		})
+		changed = true
+	}
+	if pull && !hasPullSecret(sa, name) {
+		sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{
+			Name: name,
+		})
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	_, err = clientset.Core.CoreV1().ServiceAccounts(namespace).Update(ctx, sa, metav1.UpdateOptions{})
+	return err
+}
+
+// unlinkSecret removes all references to the secret from the ServiceAccount.
+// It returns true if the ServiceAccount has been changed.
+func unlinkSecret(sa *corev1.ServiceAccount, name string) bool {
+	changed := false
+	secrets := sa.Secrets[:0]
+	for _, s := range sa.Secrets {
+		if s.Name == name {
+			changed = true
+			continue
+		}
+		secrets = append(secrets, s)
+	}
+	sa.Secrets = secrets
+	pullSecrets := sa.ImagePullSecrets[:0]
+	for _, s := range sa.ImagePullSecrets {
+		if s.Name == name {
+			changed = true
+			continue
+		}
+		pullSecrets = append(pullSecrets, s)
+	}
+	sa.ImagePullSecrets = pullSecrets
+	return changed
+}
+
+func hasSecret(sa *corev1.ServiceAccount, name string) bool {
+	for _, s := range sa.Secrets {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPullSecret(sa *corev1.ServiceAccount, name string) bool {
+	for _, s := range sa.ImagePullSecrets {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -14,10 +14,33 @@
 
 package credential
 
+const (
+	// LabelKey is the label of secrets with credentials created by tm,
+	// label value is the type of credentials
+	LabelKey      = "cli.triggermesh.io/credentials"
+	gitLabel      = "git"
+	registryLabel = "registry"
+
+	defaultServiceAccount = "default"
+)
+
+// Credentials represents secrets with registry and git credentials created by tm
+type Credentials struct {
+	Name      string
+	Namespace string
+}
+
+// GitCreds contains git host credentials: either HTTPS token or SSH private key
 type GitCreds struct {
 	Namespace string
-	// Host string
+	Host      string
+	// Username is used with the token for HTTPS basic authentication
+	Username string
+	Token    string
+	// Key is SSH private key
 	Key string
+	// ServiceAccount to link the secret to, "default" if empty
+	ServiceAccount string
 }
 
 // RegistryCreds contains docker registry credentials
@@ -30,4 +53,8 @@ type RegistryCreds struct {
 	Password  string
 	Pull      bool
 	Push      bool
+	// DockerConfig is the path to the local docker config to import credentials from
+	DockerConfig string
+	// ServiceAccount to link the secret to, "default" if empty
+	ServiceAccount string
 }
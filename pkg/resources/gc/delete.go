@@ -0,0 +1,63 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/pipelineresource"
+	"github.com/triggermesh/tm/pkg/resources/task"
+	"github.com/triggermesh/tm/pkg/resources/taskrun"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Delete removes collected items and returns the list of successfully removed ones.
+// Deletion continues on failures, all errors are returned together.
+func (g *GC) Delete(clientset *client.ConfigSet, items []Item) ([]Item, error) {
+	var removed []Item
+	var errs []string
+	for _, item := range items {
+		var err error
+		switch item.Kind {
+		case KindTask:
+			t := task.Task{Name: item.Name, Namespace: g.Namespace}
+			err = t.Delete(clientset)
+		case KindTaskRun:
+			tr := taskrun.TaskRun{Name: item.Name, Namespace: g.Namespace}
+			err = tr.Delete(clientset)
+		case KindPipelineResource:
+			plr := pipelineresource.PipelineResource{Name: item.Name, Namespace: g.Namespace}
+			err = plr.Delete(clientset)
+		case KindLocal:
+			err = os.RemoveAll(item.Name)
+		default:
+			err = fmt.Errorf("unknown kind")
+		}
+		// object may be already removed by kubernetes garbage collector
+		if err != nil && !k8serrors.IsNotFound(err) {
+			errs = append(errs, fmt.Sprintf("%s %q: %s", item.Kind, item.Name, err))
+			continue
+		}
+		clientset.Log.Debugf("%s %q removed", item.Kind, item.Name)
+		removed = append(removed, item)
+	}
+	if len(errs) != 0 {
+		return removed, fmt.Errorf("failed to remove: %s", strings.Join(errs, "; "))
+	}
+	return removed, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestParseAge(t *testing.T) {
+	testCases := []struct {
+		age      string
+		expected time.Duration
+		err      bool
+	}{
+		{"7d", 7 * 24 * time.Hour, false},
+		{"0d", 0, false},
+		{"12h", 12 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"d", 0, true},
+		{"week", 0, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.age, func(t *testing.T) {
+			result, err := ParseAge(tc.age)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestHasBuildOwner(t *testing.T) {
+	testCases := []struct {
+		name     string
+		owners   []metav1.OwnerReference
+		expected bool
+	}{
+		{"no owners", nil, false},
+		{"taskrun", []metav1.OwnerReference{{APIVersion: "tekton.dev/v1beta1", Kind: "TaskRun"}}, true},
+		{"knative service", []metav1.OwnerReference{{APIVersion: "serving.knative.dev/v1", Kind: "Service"}}, true},
+		{"core service", []metav1.OwnerReference{{APIVersion: "v1", Kind: "Service"}}, false},
+		{"deployment", []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment"}}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, hasBuildOwner(tc.owners))
+		})
+	}
+}
+
+func TestOwnerExists(t *testing.T) {
+	meta := func(uid string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: "foo", Namespace: "test-namespace", UID: types.UID(uid)}
+	}
+	clientset := fake.NewClient(
+		&servingv1.Service{ObjectMeta: meta("service-uid")},
+		&servingv1.Configuration{ObjectMeta: meta("configuration-uid")},
+	)
+	testCases := []struct {
+		name     string
+		owner    metav1.OwnerReference
+		expected bool
+	}{
+		{"service", metav1.OwnerReference{Kind: "Service", Name: "foo", UID: "service-uid"}, true},
+		{"configuration", metav1.OwnerReference{Kind: "Configuration", Name: "foo", UID: "configuration-uid"}, true},
+		{"legacy service reference", metav1.OwnerReference{Kind: "Configuration", Name: "foo", UID: "service-uid"}, true},
+		{"recreated service", metav1.OwnerReference{Kind: "Service", Name: "foo", UID: "old-uid"}, false},
+		{"missing service", metav1.OwnerReference{Kind: "Service", Name: "bar", UID: "service-uid"}, false},
+	}
+	g := &GC{Namespace: "test-namespace"}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exists, err := g.ownerExists(&clientset, tc.owner)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, exists)
+		})
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/printer"
+	"github.com/triggermesh/tm/pkg/resources/pipelineresource"
+	"github.com/triggermesh/tm/pkg/resources/task"
+	"github.com/triggermesh/tm/pkg/resources/taskrun"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// ParseAge parses duration string with optional days suffix, e.g. "7d" or "12h"
+func ParseAge(age string) (time.Duration, error) {
+	if strings.HasSuffix(age, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(age, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", age)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(age)
+}
+
+// List returns build objects and local paths to be collected
+func (g *GC) List(clientset *client.ConfigSet) ([]Item, error) {
+	var items []Item
+
+	pipelines, err := clientset.Has(client.TektonPipelines)
+	if err != nil {
+		return nil, err
+	}
+	// Tekton may be missing in clusters where services are deployed from images only
+	if pipelines {
+		t := task.Task{Namespace: g.Namespace}
+		tasks, err := t.List(clientset)
+		if err != nil {
+			return nil, fmt.Errorf("listing tasks: %s", err)
+		}
+		for _, obj := range tasks.Items {
+			if item, ok, err := g.check(clientset, KindTask, &obj); err != nil {
+				return nil, err
+			} else if ok {
+				items = append(items, item)
+			}
+		}
+
+		tr := taskrun.TaskRun{Namespace: g.Namespace}
+		taskruns, err := tr.List(clientset)
+		if err != nil {
+			return nil, fmt.Errorf("listing taskruns: %s", err)
+		}
+		for _, obj := range taskruns.Items {
+			// running builds are never collected
+			if !obj.IsDone() {
+				continue
+			}
+			if item, ok, err := g.check(clientset, KindTaskRun, &obj); err != nil {
+				return nil, err
+			} else if ok {
+				items = append(items, item)
+			}
+		}
+	}
+
+	resourcesInstalled, err := clientset.Has(client.TektonResources)
+	if err != nil {
+		return nil, err
+	}
+	if resourcesInstalled {
+		plr := pipelineresource.PipelineResource{Namespace: g.Namespace}
+		resources, err := plr.List(clientset)
+		if err != nil {
+			return nil, fmt.Errorf("listing pipelineresources: %s", err)
+		}
+		for _, obj := range resources.Items {
+			if item, ok, err := g.check(clientset, KindPipelineResource, &obj); err != nil {
+				return nil, err
+			} else if ok {
+				items = append(items, item)
+			}
+		}
+	}
+
+	local, err := g.localItems()
+	if err != nil {
+		return nil, err
+	}
+	return append(items, local...), nil
+}
+
+// check returns true if object is created by tm, it is older than GC threshold
+// and it has no owners or all its owners are missing
+func (g *GC) check(clientset *client.ConfigSet, kind string, obj metav1.Object) (Item, bool, error) {
+	item := Item{
+		Kind: kind,
+		Name: obj.GetName(),
+		Age:  time.Since(obj.GetCreationTimestamp().Time),
+	}
+	if item.Age < g.OlderThan {
+		return item, false, nil
+	}
+	owners := obj.GetOwnerReferences()
+	if _, labeled := obj.GetLabels()[task.BuildLabelKey]; !labeled && !hasBuildOwner(owners) {
+		return item, false, nil
+	}
+	if len(owners) == 0 {
+		item.Reason = "no owner"
+		return item, true, nil
+	}
+	var missing []string
+	for _, owner := range owners {
+		exists, err := g.ownerExists(clientset, owner)
+		if err != nil {
+			return item, false, fmt.Errorf("checking %s %q owner: %s", kind, item.Name, err)
+		}
+		if exists {
+			return item, false, nil
+		}
+		missing = append(missing, owner.Kind+"/"+owner.Name)
+	}
+	item.Reason = "owner " + strings.Join(missing, ", ") + " not found"
+	return item, true, nil
+}
+
+// hasBuildOwner returns true if object has owner references that tm sets for build objects
+func hasBuildOwner(owners []metav1.OwnerReference) bool {
+	for _, owner := range owners {
+		switch {
+		case owner.Kind == "TaskRun" && strings.HasPrefix(owner.APIVersion, "tekton.dev/"),
+			(owner.Kind == "Service" || owner.Kind == "Configuration") && strings.HasPrefix(owner.APIVersion, "serving.knative.dev/"):
+			return true
+		}
+	}
+	return false
+}
+
+// ownerExists checks that owner object exists and has the same UID.
+// Owners of unknown kinds are considered existing.
+func (g *GC) ownerExists(clientset *client.ConfigSet, owner metav1.OwnerReference) (bool, error) {
+	ctx := clientset.Context
+	var uid types.UID
+	var err error
+	switch owner.Kind {
+	case "TaskRun":
+		var obj metav1.Object
+		obj, err = clientset.TektonTasks.TektonV1beta1().TaskRuns(g.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err == nil {
+			uid = obj.GetUID()
+		}
+	case "Service":
+		var obj metav1.Object
+		obj, err = clientset.Serving.ServingV1().Services(g.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err == nil {
+			uid = obj.GetUID()
+		}
+	case "Configuration":
+		var obj metav1.Object
+		obj, err = clientset.Serving.ServingV1().Configurations(g.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err == nil && obj.GetUID() != owner.UID {
+			// older tm versions set Configuration kind with the Service UID
+			obj, err = clientset.Serving.ServingV1().Services(g.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		}
+		if err == nil {
+			uid = obj.GetUID()
+		}
+	default:
+		return true, nil
+	}
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return uid == owner.UID, nil
+}
+
+// localItems returns temporary files and directories older than GC threshold
+func (g *GC) localItems() ([]Item, error) {
+	var items []Item
+	for _, dir := range file.TempDirs() {
+		entries, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			age := time.Since(entry.ModTime())
+			if age < g.OlderThan {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			items = append(items, Item{
+				Kind:   KindLocal,
+				Name:   path,
+				Age:    age,
+				Reason: "temporary file",
+				Size:   pathSize(path),
+			})
+		}
+	}
+	return items, nil
+}
+
+func pathSize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// GetTable converts collected items into printable table
+func (g *GC) GetTable(items []Item) printer.Table {
+	table := printer.Table{
+		Headers: []string{"Kind", "Name", "Age", "Reason"},
+		Rows:    make([][]string, 0, len(items)),
+	}
+	for _, item := range items {
+		reason := item.Reason
+		if item.Kind == KindLocal {
+			reason = fmt.Sprintf("%s, %s", reason, file.HumanBytes(item.Size))
+		}
+		table.Rows = append(table.Rows, []string{
+			item.Kind,
+			item.Name,
+			duration.HumanDuration(item.Age),
+			reason,
+		})
+	}
+	return table
+}
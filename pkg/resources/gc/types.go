@@ -0,0 +1,43 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import "time"
+
+// Kinds of collected garbage
+const (
+	KindTask             = "Task"
+	KindTaskRun          = "TaskRun"
+	KindPipelineResource = "PipelineResource"
+	KindLocal            = "Local"
+)
+
+// GC finds build objects created by tm in the namespace which owners are missing
+// or stale, and local temporary files left by builds
+type GC struct {
+	Namespace string
+	// OlderThan is the minimum age of collected objects
+	OlderThan time.Duration
+}
+
+// Item is a single collected object or local path
+type Item struct {
+	Kind   string
+	Name   string
+	Age    time.Duration
+	Reason string
+	// Size of the local path in bytes
+	Size int64
+}
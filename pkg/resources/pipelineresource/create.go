@@ -15,8 +15,6 @@
 package pipelineresource
 
 import (
-	"context"
-
 	v1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/triggermesh/tm/pkg/client"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -29,6 +27,9 @@ func (plr *PipelineResource) Deploy(clientset *client.ConfigSet) (*v1alpha1.Pipe
 	if client.Dry {
 		return &pipelineResourceObject, nil
 	}
+	if err := clientset.Require(client.TektonResources); err != nil {
+		return nil, err
+	}
 	return plr.createOrUpdate(pipelineResourceObject, clientset)
 }
 
@@ -54,25 +55,25 @@ func (plr *PipelineResource) newObject(clientset *client.ConfigSet) v1alpha1.Pip
 
 func (plr *PipelineResource) createOrUpdate(pipelineResourceObject v1alpha1.PipelineResource, clientset *client.ConfigSet) (*v1alpha1.PipelineResource, error) {
 	var pipeline *v1alpha1.PipelineResource
-	res, err := clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).Create(context.Background(), &pipelineResourceObject, metav1.CreateOptions{})
+	res, err := clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).Create(clientset.Context, &pipelineResourceObject, metav1.CreateOptions{})
 	if k8serrors.IsAlreadyExists(err) {
-		pipeline, err = clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).Get(context.Background(), pipelineResourceObject.ObjectMeta.Name, metav1.GetOptions{})
+		pipeline, err = clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).Get(clientset.Context, pipelineResourceObject.ObjectMeta.Name, metav1.GetOptions{})
 		if err != nil {
 			return res, err
 		}
 		pipelineResourceObject.ObjectMeta.ResourceVersion = pipeline.GetResourceVersion()
-		res, err = clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).Update(context.Background(), &pipelineResourceObject, metav1.UpdateOptions{})
+		res, err = clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).Update(clientset.Context, &pipelineResourceObject, metav1.UpdateOptions{})
 	}
 	return res, err
 }
 
 // SetOwner updates PipelineResource object with provided owner reference
 func (plr *PipelineResource) SetOwner(clientset *client.ConfigSet, owner metav1.OwnerReference) error {
-	pplresource, err := clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).Get(context.Background(), plr.Name, metav1.GetOptions{})
+	pplresource, err := clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).Get(clientset.Context, plr.Name, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
 	pplresource.SetOwnerReferences([]metav1.OwnerReference{owner})
-	_, err = clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).Update(context.Background(), pplresource, metav1.UpdateOptions{})
+	_, err = clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).Update(clientset.Context, pplresource, metav1.UpdateOptions{})
 	return err
 }
@@ -15,12 +15,11 @@
 package pipelineresource
 
 import (
-	"context"
-
 	v1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/triggermesh/tm/pkg/client"
 	"github.com/triggermesh/tm/pkg/printer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 // GetTable converts k8s list instance into printable object
@@ -53,5 +52,20 @@ func (plr *PipelineResource) row(item *v1alpha1.PipelineResource) []string {
 
 // List returns k8s list object
 func (plr *PipelineResource) List(clientset *client.ConfigSet) (*v1alpha1.PipelineResourceList, error) {
-	return clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).List(context.Background(), metav1.ListOptions{})
+	list := &v1alpha1.PipelineResourceList{}
+	err := client.ListPages(client.ListOptions(), func(opts metav1.ListOptions) (string, error) {
+		page, err := clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).List(clientset.Context, opts)
+		if err != nil {
+			return "", err
+		}
+		list.Items = append(list.Items, page.Items...)
+		list.ResourceVersion = page.ResourceVersion
+		return page.Continue, nil
+	})
+	return list, err
+}
+
+// Watch opens watch of pipeline resources changes
+func (plr *PipelineResource) Watch(clientset *client.ConfigSet, opts metav1.ListOptions) (watch.Interface, error) {
+	return clientset.TektonPipelines.TektonV1alpha1().PipelineResources(plr.Namespace).Watch(clientset.Context, opts)
 }
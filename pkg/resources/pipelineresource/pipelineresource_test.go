@@ -19,7 +19,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	v1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // func TestCreate(t *testing.T) {
@@ -53,13 +56,13 @@ func TestList(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewClient(&v1alpha1.PipelineResource{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: namespace}})
 
 	pipeline := &PipelineResource{Name: "Foo", Namespace: namespace}
 
-	_, err = pipeline.List(&testClient)
+	list, err := pipeline.List(&testClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
@@ -67,13 +70,11 @@ func TestGet(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewClient()
 
 	pipeline := &PipelineResource{Name: "Foo", Namespace: namespace}
-	result, err := pipeline.Get(&testClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	_, err := pipeline.Get(&testClient)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
 
 func TestDelete(t *testing.T) {
@@ -81,10 +82,9 @@ func TestDelete(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewClient()
 
 	pipeline := &PipelineResource{Name: "Foo", Namespace: namespace}
-	err = pipeline.Delete(&testClient)
+	err := pipeline.Delete(&testClient)
 	assert.Error(t, err)
 }
@@ -15,7 +15,6 @@
 package revision
 
 import (
-	"context"
 	"fmt"
 	"time"
 
@@ -23,6 +22,7 @@ import (
 	"github.com/triggermesh/tm/pkg/printer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/watch"
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
@@ -75,5 +75,20 @@ func (r *Revision) row(item *servingv1.Revision) []string {
 
 // List returns k8s list object
 func (r *Revision) List(clientset *client.ConfigSet) (*servingv1.RevisionList, error) {
-	return clientset.Serving.ServingV1().Revisions(r.Namespace).List(context.Background(), metav1.ListOptions{})
+	list := &servingv1.RevisionList{}
+	err := client.ListPages(client.ListOptions(), func(opts metav1.ListOptions) (string, error) {
+		page, err := clientset.Serving.ServingV1().Revisions(r.Namespace).List(clientset.Context, opts)
+		if err != nil {
+			return "", err
+		}
+		list.Items = append(list.Items, page.Items...)
+		list.ResourceVersion = page.ResourceVersion
+		return page.Continue, nil
+	})
+	return list, err
+}
+
+// Watch opens watch of revisions changes
+func (r *Revision) Watch(clientset *client.ConfigSet, opts metav1.ListOptions) (watch.Interface, error) {
+	return clientset.Serving.ServingV1().Revisions(r.Namespace).Watch(clientset.Context, opts)
 }
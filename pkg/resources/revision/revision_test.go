@@ -19,7 +19,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
 func TestList(t *testing.T) {
@@ -28,13 +31,13 @@ func TestList(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	revisionClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	revisionClient := fake.NewClient(&servingv1.Revision{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: namespace}})
 
 	revision := &Revision{Name: "Foo", Namespace: namespace}
 
-	_, err = revision.List(&revisionClient)
+	list, err := revision.List(&revisionClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
@@ -43,13 +46,11 @@ func TestGet(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	revisionClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	revisionClient := fake.NewClient()
 
 	revision := &Revision{Name: "Foo", Namespace: namespace}
-	result, err := revision.Get(&revisionClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	_, err := revision.Get(&revisionClient)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
 
 func TestDelete(t *testing.T) {
@@ -58,10 +59,9 @@ func TestDelete(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	revisionClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	revisionClient := fake.NewClient()
 
 	revision := &Revision{Name: "Foo", Namespace: namespace}
-	err = revision.Delete(&revisionClient)
+	err := revision.Delete(&revisionClient)
 	assert.Error(t, err)
 }
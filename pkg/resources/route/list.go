@@ -15,12 +15,12 @@
 package route
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/triggermesh/tm/pkg/client"
 	"github.com/triggermesh/tm/pkg/printer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
@@ -67,5 +67,20 @@ func (rt *Route) row(item *servingv1.Route) []string {
 
 // List returns k8s list object
 func (rt *Route) List(clientset *client.ConfigSet) (*servingv1.RouteList, error) {
-	return clientset.Serving.ServingV1().Routes(rt.Namespace).List(context.Background(), metav1.ListOptions{})
+	list := &servingv1.RouteList{}
+	err := client.ListPages(client.ListOptions(), func(opts metav1.ListOptions) (string, error) {
+		page, err := clientset.Serving.ServingV1().Routes(rt.Namespace).List(clientset.Context, opts)
+		if err != nil {
+			return "", err
+		}
+		list.Items = append(list.Items, page.Items...)
+		list.ResourceVersion = page.ResourceVersion
+		return page.Continue, nil
+	})
+	return list, err
+}
+
+// Watch opens watch of routes changes
+func (rt *Route) Watch(clientset *client.ConfigSet, opts metav1.ListOptions) (watch.Interface, error) {
+	return clientset.Serving.ServingV1().Routes(rt.Namespace).Watch(clientset.Context, opts)
 }
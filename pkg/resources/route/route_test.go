@@ -19,7 +19,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
 func TestList(t *testing.T) {
@@ -27,13 +30,13 @@ func TestList(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	routeClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	routeClient := fake.NewClient(&servingv1.Route{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: namespace}})
 
 	r := &Route{Name: "Foo", Namespace: namespace}
 
-	_, err = r.List(&routeClient)
+	list, err := r.List(&routeClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
@@ -41,13 +44,11 @@ func TestGet(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	routeClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	routeClient := fake.NewClient()
 
 	r := &Route{Name: "Foo", Namespace: namespace}
-	result, err := r.Get(&routeClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	_, err := r.Get(&routeClient)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
 
 func TestDelete(t *testing.T) {
@@ -55,10 +56,9 @@ func TestDelete(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	routeClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	routeClient := fake.NewClient()
 
 	r := &Route{Name: "Foo", Namespace: namespace}
-	err = r.Delete(&routeClient)
+	err := r.Delete(&routeClient)
 	assert.Error(t, err)
 }
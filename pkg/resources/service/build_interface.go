@@ -20,6 +20,7 @@ import (
 	"github.com/triggermesh/tm/pkg/resources/clustertask"
 	"github.com/triggermesh/tm/pkg/resources/task"
 	"github.com/triggermesh/tm/pkg/resources/taskrun"
+	"github.com/triggermesh/tm/pkg/runtimes"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -44,6 +45,16 @@ func NewBuilder(clientset *client.ConfigSet, s *Service) Builder {
 		return s.taskRun()
 	}
 
+	if _, ok := runtimes.ParseReference(s.Runtime); ok && !file.IsLocal(s.Runtime) {
+		clientset.Log.Debugf("runtime %q is seemed to be a catalog reference, resolving", s.Runtime)
+		if localFile, err := runtimes.Resolve(s.Runtime); err != nil {
+			clientset.Log.Warnf("Warning! Cannot resolve runtime: %s\n", err)
+		} else {
+			s.Runtime = localFile
+			return s.taskRun()
+		}
+	}
+
 	if file.IsRemote(s.Runtime) {
 		clientset.Log.Debugf("runtime %q is seemed to be a remote file, downloading", s.Runtime)
 		if localFile, err := file.Download(s.Runtime); err != nil {
@@ -68,7 +79,12 @@ func (s *Service) taskRun() *taskrun.TaskRun {
 		Task: taskrun.Resource{
 			Name: s.Runtime,
 		},
-		Timeout: s.BuildTimeout,
-		Wait:    true,
+		Timeout:        s.BuildTimeout,
+		Retries:        s.BuildRetries,
+		Wait:           true,
+		WorkspaceSize:  s.WorkspaceSize,
+		Cache:          s.Cache,
+		OnPhase:        s.OnPhase,
+		ServiceAccount: s.buildServiceAccountName(),
 	}
 }
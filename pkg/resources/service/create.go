@@ -20,17 +20,21 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/ghodss/yaml"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 
 	"knative.dev/pkg/apis"
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
 )
 
 // time duration to wait for knative service ready state
@@ -38,7 +42,37 @@ const ksvcWaitTimeout = 10 * time.Minute
 
 // Deploy receives Service structure and generate knative/service object to deploy it in knative cluster
 func (s *Service) Deploy(clientset *client.ConfigSet) (string, error) {
-	var err error
+	image, builder, err := s.build(clientset)
+	if err != nil {
+		return "", err
+	}
+	if s.BuildOnly {
+		return fmt.Sprintf("Build-only flag set, service image is %s", image), nil
+	}
+	return s.deployImage(image, builder, clientset)
+}
+
+// build runs the service image builder, if it is required, and returns resulting image
+func (s *Service) build(clientset *client.ConfigSet) (string, Builder, error) {
+	image := s.Source
+	builder := NewBuilder(clientset, s)
+	if builder == nil || client.Dry {
+		return image, builder, nil
+	}
+	if err := clientset.Require(client.TektonPipelines); err != nil {
+		return "", builder, err
+	}
+	s.phase(printer.PhaseBuild)
+	image, err := builder.Deploy(clientset)
+	if err != nil {
+		return "", builder, fmt.Errorf("Deploying builder: %s", err)
+	}
+	clientset.Log.Debugf("image is ready, creating service")
+	return image, builder, nil
+}
+
+// deployImage creates or updates knative service with provided image
+func (s *Service) deployImage(image string, builder Builder, clientset *client.ConfigSet) (_ string, err error) {
 	service := &servingv1.Service{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Service",
@@ -46,34 +80,24 @@ func (s *Service) Deploy(clientset *client.ConfigSet) (string, error) {
 		},
 	}
 
-	image := s.Source
-	builder := NewBuilder(clientset, s)
-
 	if builder != nil && !client.Dry {
 		defer func() {
+			// service was not created, build is left without owner for "tm gc"
+			if service == nil || err != nil {
+				return
+			}
 			owner := metav1.OwnerReference{
 				APIVersion: "serving.knative.dev/v1",
-				Kind:       "Configuration",
+				Kind:       "Service",
 				Name:       service.GetName(),
 				UID:        service.GetUID(),
 			}
 			if err := builder.SetOwner(clientset, owner); err != nil {
-				// fmt.Printf("Can't set builder owner\n")
-				// if err = builder.Delete(clientset); err != nil {
-				// fmt.Printf("Can't remove builder: %s\n", err)
-				// }
+				clientset.Log.Warnf("Can't set build owner, run \"tm gc\" to cleanup stale builds: %s", err)
 			}
 		}()
-
-		if image, err = builder.Deploy(clientset); err != nil {
-			return "", fmt.Errorf("Deploying builder: %s", err)
-		}
-	}
-	clientset.Log.Debugf("image is ready, creating service")
-
-	if s.BuildOnly {
-		return fmt.Sprintf("Build-only flag set, service image is %s", image), nil
 	}
+	s.phase(printer.PhaseDeploy)
 
 	concurrency := int64(s.Concurrency)
 	configuration := servingv1.ConfigurationSpec{
@@ -100,6 +124,8 @@ func (s *Service) Deploy(clientset *client.ConfigSet) (string, error) {
 	configuration.Template.Spec.PodSpec.Containers[0].Env = s.setupEnv()
 	configuration.Template.Spec.PodSpec.Containers[0].EnvFrom = s.setupEnvSecrets()
 	configuration.Template.Spec.PodSpec.Containers[0].ImagePullPolicy = corev1.PullPolicy(s.PullPolicy)
+	configuration.Template.Spec.PodSpec.Volumes, configuration.Template.Spec.PodSpec.Containers[0].VolumeMounts = s.setupSecretMounts()
+	configuration.Template.Spec.PodSpec.ServiceAccountName = s.serviceAccountName()
 
 	service.ObjectMeta = metav1.ObjectMeta{
 		Name:              s.Name,
@@ -121,14 +147,34 @@ func (s *Service) Deploy(clientset *client.ConfigSet) (string, error) {
 		return string(obj), err
 	}
 
+	if err := clientset.Require(client.KnativeServing); err != nil {
+		return "", err
+	}
+	if len(s.Schedule) != 0 {
+		if err := clientset.Require(client.KnativeSources); err != nil {
+			return "", err
+		}
+	}
+	if err = s.applyServiceAccount(clientset); err != nil {
+		return "", fmt.Errorf("Service account: %s", err)
+	}
 	if service, err = s.createOrUpdate(service, clientset); err != nil {
 		return "", fmt.Errorf("Creating service: %s", err)
 	}
+	s.deployed = service
+	// permissions removed from the manifest, service does not use the dedicated account anymore
+	if len(s.Permissions) == 0 {
+		if err := s.deleteServiceAccount(clientset, s.ServiceAccount); err != nil {
+			clientset.Log.Warnf("Failed to remove function service account: %v", err)
+		}
+	}
 
 	// before creating PingSources remove old ones
 	// to make sure that we're in sync with manifest
-	if err := s.removePingSources(service.UID, clientset); err != nil {
-		clientset.Log.Warnf("Failed to remove schedule: %v", err)
+	if ok, _ := clientset.Has(client.KnativeSources); ok {
+		if err := s.removePingSources(service.UID, clientset); err != nil {
+			clientset.Log.Warnf("Failed to remove schedule: %v", err)
+		}
 	}
 
 	for _, sched := range s.Schedule {
@@ -145,8 +191,12 @@ func (s *Service) Deploy(clientset *client.ConfigSet) (string, error) {
 	}
 
 	clientset.Log.Infof("Waiting for service %q ready state", s.Name)
-	domain, err := s.wait(clientset)
-	return fmt.Sprintf("Service %s URL: %s", s.Name, domain), err
+	ready, err := s.wait(clientset)
+	if err != nil {
+		return fmt.Sprintf("Service %s URL: ", s.Name), err
+	}
+	s.deployed = ready
+	return fmt.Sprintf("Service %s URL: %s", s.Name, ready.Status.URL), nil
 }
 
 func (s *Service) setupEnv() []corev1.EnvVar {
@@ -173,9 +223,37 @@ func (s *Service) setupEnvSecrets() []corev1.EnvFromSource {
 	return env
 }
 
+// setupSecretMounts returns volumes and mounts of secrets that are mounted as files
+func (s *Service) setupSecretMounts() ([]corev1.Volume, []corev1.VolumeMount) {
+	names := make([]string, 0, len(s.SecretMounts))
+	for name := range s.SecretMounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	for _, name := range names {
+		volume := "secret-" + strings.ReplaceAll(name, ".", "-")
+		volumes = append(volumes, corev1.Volume{
+			Name: volume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: name,
+				},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      volume,
+			MountPath: s.SecretMounts[name],
+			ReadOnly:  true,
+		})
+	}
+	return volumes, mounts
+}
+
 func (s *Service) createOrUpdate(serviceObject *servingv1.Service, clientset *client.ConfigSet) (*servingv1.Service, error) {
 	clientset.Log.Debugf("creating \"%s/%s\" service", s.Namespace, s.Name)
-	ctx := context.Background()
+	ctx := clientset.Context
 	newService, err := clientset.Serving.ServingV1().Services(s.Namespace).Create(ctx, serviceObject, metav1.CreateOptions{})
 	if k8serrors.IsAlreadyExists(err) {
 		clientset.Log.Debugf("service \"%s/%s\" already exist, updating", serviceObject.GetNamespace(), serviceObject.GetName())
@@ -211,77 +289,75 @@ func mapFromSlice(slice []string) map[string]string {
 	return m
 }
 
-func (s *Service) wait(clientset *client.ConfigSet) (string, error) {
-	ctx := context.Background()
-	svcWatchInterface, err := clientset.Serving.ServingV1().Services(s.Namespace).Watch(ctx, metav1.ListOptions{
-		FieldSelector: fmt.Sprintf("metadata.name=%s", s.Name),
-	})
-	if err != nil {
-		return "", err
-	}
-	if svcWatchInterface == nil {
-		return "", errors.New("can't get watch interface, please check service status")
-	}
-	defer svcWatchInterface.Stop()
-
+func (s *Service) wait(clientset *client.ConfigSet) (*servingv1.Service, error) {
 	duration, err := time.ParseDuration(s.BuildTimeout)
 	if err != nil {
 		duration = ksvcWaitTimeout
 	}
+	ctx, cancel := context.WithTimeout(clientset.Context, duration)
+	defer cancel()
 
-	ticker := time.NewTicker(duration)
-	defer ticker.Stop()
-
+	services := clientset.Serving.ServingV1().Services(s.Namespace)
+	var ready *servingv1.Service
 	firstError := true
-	for {
-		select {
-		case event := <-svcWatchInterface.ResultChan():
-			if event.Object == nil {
-				svcWatchInterface.Stop()
-				if svcWatchInterface, err = clientset.Serving.ServingV1().Services(s.Namespace).Watch(ctx, metav1.ListOptions{
-					FieldSelector: fmt.Sprintf("metadata.name=%s", s.Name),
-				}); err != nil {
-					return "", err
-				}
-				if svcWatchInterface == nil {
-					return "", errors.New("can't get watch interface, please check service status")
-				}
-				continue
-			}
-			serviceEvent, ok := event.Object.(*servingv1.Service)
-			if !ok {
-				continue
-			}
-			if clientset.Log.IsDebug() {
-				clientset.Log.Debugf("got new event:")
-				for _, v := range serviceEvent.Status.Conditions {
-					clientset.Log.Debugf(" condition: %q, status: %q, message: %q", v.Type, v.Status, v.Message)
-				}
+	var check func(*servingv1.Service) (bool, error)
+	check = func(service *servingv1.Service) (bool, error) {
+		if clientset.Log.IsDebug() {
+			clientset.Log.Debugf("got new event:")
+			for _, v := range service.Status.Conditions {
+				clientset.Log.Debugf(" condition: %q, status: %q, message: %q", v.Type, v.Status, v.Message)
 			}
-			if serviceEvent.IsReady() {
-				return serviceEvent.Status.URL.String(), nil
-			}
-			for _, v := range serviceEvent.Status.Conditions {
-				if v.IsFalse() && v.Severity == apis.ConditionSeverityError {
-					if v.Reason == "RevisionFailed" && firstError {
-						time.Sleep(time.Second * 3)
-						svcWatchInterface.Stop()
-						if svcWatchInterface, err = clientset.Serving.ServingV1().Services(s.Namespace).Watch(ctx, metav1.ListOptions{
-							FieldSelector: fmt.Sprintf("metadata.name=%s", s.Name),
-						}); err != nil {
-							return "", err
-						}
-						if svcWatchInterface == nil {
-							return "", errors.New("can't get watch interface, please check service status")
-						}
-						firstError = false
-						break
+		}
+		if service.IsReady() {
+			ready = service
+			return true, nil
+		}
+		for _, v := range service.Status.Conditions {
+			if v.IsFalse() && v.Severity == apis.ConditionSeverityError {
+				if v.Reason == "RevisionFailed" && firstError {
+					// first revision failure may be transient, check service again
+					firstError = false
+					select {
+					case <-time.After(time.Second * 3):
+					case <-ctx.Done():
+						return false, ctx.Err()
+					}
+					latest, err := services.Get(ctx, s.Name, metav1.GetOptions{})
+					if err != nil {
+						return false, err
 					}
-					return "", errors.New(v.Message)
+					return check(latest)
 				}
+				return false, errors.New(v.Message)
 			}
-		case <-ticker.C:
-			return "", fmt.Errorf("Service %q didn't become ready in time", s.Name)
 		}
+		return false, nil
+	}
+
+	err = client.Watch(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("metadata.name=%s", s.Name),
+	}, func(opts metav1.ListOptions) (watch.Interface, error) {
+		return services.Watch(ctx, opts)
+	}, func(event watch.Event) (bool, error) {
+		service, ok := event.Object.(*servingv1.Service)
+		if !ok {
+			return false, nil
+		}
+		return check(service)
+	})
+	switch {
+	case err == nil:
+		return ready, nil
+	case clientset.Context.Err() != nil:
+		return nil, fmt.Errorf("waiting for service %q: %s", s.Name, clientset.Context.Err())
+	case ctx.Err() != nil:
+		return nil, fmt.Errorf("Service %q didn't become ready in time", s.Name)
+	}
+	return nil, err
+}
+
+func (s *Service) phase(phase string) {
+	if s.OnPhase != nil {
+		s.OnPhase(phase)
 	}
 }
@@ -16,13 +16,19 @@ package service
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	servingFake "knative.dev/serving/pkg/client/clientset/versioned/fake"
 
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
 )
 
 func TestDryRunDeployment(t *testing.T) {
@@ -40,7 +46,7 @@ func TestDryRunDeployment(t *testing.T) {
 	require.NoError(t, err)
 
 	service := &Service{Namespace: namespace}
-	err = service.DeployYAML("../../../testfiles/serverless-simple.yaml", []string{}, 3, &clientset)
+	_, err = service.DeployYAML("../../../testfiles/serverless-simple.yaml", []string{}, 3, &clientset)
 	require.NoError(t, err)
 
 	output := buffer.String()
@@ -48,3 +54,43 @@ func TestDryRunDeployment(t *testing.T) {
 	assert.Contains(t, output, "\"apiVersion\": \"serving.knative.dev/v1\"")
 	assert.Contains(t, output, "\"image\": \"docker.io/hello-world\"")
 }
+
+// ownerRecorder is the Builder that records build owners
+type ownerRecorder struct {
+	owners []metav1.OwnerReference
+}
+
+func (b *ownerRecorder) Deploy(clientset *client.ConfigSet) (string, error) {
+	return "", nil
+}
+
+func (b *ownerRecorder) SetOwner(clientset *client.ConfigSet, owner metav1.OwnerReference) error {
+	b.owners = append(b.owners, owner)
+	return nil
+}
+
+func (b *ownerRecorder) Delete(clientset *client.ConfigSet) error {
+	return nil
+}
+
+func TestDeployImageBuildOwner(t *testing.T) {
+	client.Dry = false
+	client.Wait = false
+	s := &Service{Name: "foo", Namespace: "test-namespace"}
+
+	clientset := fake.NewClient()
+	clientset.Serving.(*servingFake.Clientset).PrependReactor("create", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("admission webhook denied the request")
+	})
+	builder := &ownerRecorder{}
+	_, err := s.deployImage("registry/foo", builder, &clientset)
+	assert.Error(t, err)
+	assert.Empty(t, builder.owners)
+
+	clientset = fake.NewClient()
+	_, err = s.deployImage("registry/foo", builder, &clientset)
+	assert.NoError(t, err)
+	require.Len(t, builder.owners, 1)
+	assert.Equal(t, "Service", builder.owners[0].Kind)
+	assert.Equal(t, "foo", builder.owners[0].Name)
+}
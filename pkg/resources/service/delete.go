@@ -15,13 +15,19 @@
 package service
 
 import (
-	"context"
+	"fmt"
 
 	"github.com/triggermesh/tm/pkg/client"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// Delete removes knative service object
+// Delete removes knative service object and its ServiceAccount and RBAC objects created by tm
 func (s *Service) Delete(clientset *client.ConfigSet) error {
-	return clientset.Serving.ServingV1().Services(s.Namespace).Delete(context.Background(), s.Name, metav1.DeleteOptions{})
+	if err := clientset.Serving.ServingV1().Services(s.Namespace).Delete(clientset.Context, s.Name, metav1.DeleteOptions{}); err != nil {
+		return err
+	}
+	if err := s.deleteServiceAccount(clientset, ""); err != nil {
+		return fmt.Errorf("removing service account: %s", err)
+	}
+	return nil
 }
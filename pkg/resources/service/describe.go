@@ -0,0 +1,383 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
+	"github.com/triggermesh/tm/pkg/resources/task"
+	"github.com/triggermesh/tm/pkg/resources/taskrun"
+)
+
+// Description is the service with its related objects shown by "tm describe service"
+type Description struct {
+	Service     *servingv1.Service     `json:"service"`
+	Revisions   []servingv1.Revision   `json:"revisions,omitempty"`
+	PingSources []sourcesv1.PingSource `json:"pingSources,omitempty"`
+	Triggers    []eventingv1.Trigger   `json:"triggers,omitempty"`
+	// Build is the TaskRun that produced current service image
+	Build  *v1beta1.TaskRun `json:"build,omitempty"`
+	Events []corev1.Event   `json:"events,omitempty"`
+}
+
+// Describe collects the service, its revisions, event sources, build and related events.
+// Objects of the components that are not installed in the cluster are skipped
+func (s *Service) Describe(clientset *client.ConfigSet) (*Description, error) {
+	service, err := s.Get(clientset)
+	if err != nil {
+		return nil, err
+	}
+	d := &Description{Service: service}
+	owned := metav1.ListOptions{LabelSelector: serving.ServiceLabelKey + "=" + s.Name}
+
+	revisions, err := clientset.Serving.ServingV1().Revisions(s.Namespace).List(clientset.Context, owned)
+	if err != nil {
+		return nil, fmt.Errorf("listing revisions: %s", err)
+	}
+	d.Revisions = revisions.Items
+	sort.SliceStable(d.Revisions, func(i, j int) bool {
+		return d.Revisions[j].CreationTimestamp.Before(&d.Revisions[i].CreationTimestamp)
+	})
+
+	if ok, err := clientset.Has(client.KnativeSources); err != nil {
+		return nil, err
+	} else if ok {
+		pingSources, err := clientset.Eventing.SourcesV1().PingSources(s.Namespace).List(clientset.Context, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("listing PingSources: %s", err)
+		}
+		for _, ps := range pingSources.Items {
+			if ownedBy(&ps, service.UID) {
+				d.PingSources = append(d.PingSources, ps)
+			}
+		}
+	}
+
+	if ok, err := clientset.Has(client.KnativeTriggers); err != nil {
+		return nil, err
+	} else if ok {
+		triggers, err := clientset.Eventing.EventingV1().Triggers(s.Namespace).List(clientset.Context, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("listing triggers: %s", err)
+		}
+		for _, trigger := range triggers.Items {
+			if ownedBy(&trigger, service.UID) || s.isSubscriber(trigger.Spec.Subscriber) {
+				d.Triggers = append(d.Triggers, trigger)
+			}
+		}
+	}
+
+	if ok, err := clientset.Has(client.TektonPipelines); err != nil {
+		return nil, err
+	} else if ok {
+		taskruns, err := clientset.TektonTasks.TektonV1beta1().TaskRuns(s.Namespace).List(clientset.Context, metav1.ListOptions{
+			LabelSelector: task.BuildLabelKey + "=true",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing taskruns: %s", err)
+		}
+		image := serviceImage(service)
+		for i, tr := range taskruns.Items {
+			if !ownedBy(&tr, service.UID) || image == "" || taskrun.Image(&tr) != image {
+				continue
+			}
+			if d.Build == nil || d.Build.CreationTimestamp.Before(&tr.CreationTimestamp) {
+				d.Build = &taskruns.Items[i]
+			}
+		}
+	}
+
+	if d.Events, err = s.events(clientset, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// events returns events of the service, its revisions and pods sorted by time
+func (s *Service) events(clientset *client.ConfigSet, d *Description) ([]corev1.Event, error) {
+	uids := map[types.UID]bool{d.Service.UID: true}
+	for _, revision := range d.Revisions {
+		uids[revision.UID] = true
+	}
+	pods, err := clientset.Core.CoreV1().Pods(s.Namespace).List(clientset.Context, metav1.ListOptions{
+		LabelSelector: serving.ServiceLabelKey + "=" + s.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing pods: %s", err)
+	}
+	for _, pod := range pods.Items {
+		uids[pod.UID] = true
+	}
+
+	var events []corev1.Event
+	for uid := range uids {
+		list, err := clientset.Core.CoreV1().Events(s.Namespace).List(clientset.Context, metav1.ListOptions{
+			FieldSelector: "involvedObject.uid=" + string(uid),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing events: %s", err)
+		}
+		for _, event := range list.Items {
+			// fake clientsets ignore field selectors
+			if event.InvolvedObject.UID == uid {
+				events = append(events, event)
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	return events, nil
+}
+
+func (s *Service) isSubscriber(destination duckv1.Destination) bool {
+	ref := destination.Ref
+	return ref != nil && ref.Kind == "Service" && ref.Name == s.Name &&
+		strings.HasPrefix(ref.APIVersion, serving.GroupName)
+}
+
+// isServiceOwner returns true if the reference points to the knative service.
+// Older tm versions referenced services with Configuration kind.
+func isServiceOwner(owner metav1.OwnerReference) bool {
+	return (owner.Kind == "Service" || owner.Kind == "Configuration") &&
+		strings.HasPrefix(owner.APIVersion, serving.GroupName)
+}
+
+func ownedBy(object metav1.Object, uid types.UID) bool {
+	for _, owner := range object.GetOwnerReferences() {
+		if owner.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+func serviceImage(service *servingv1.Service) string {
+	if containers := service.Spec.Template.Spec.Containers; len(containers) != 0 {
+		return containers[0].Image
+	}
+	return ""
+}
+
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// Print writes human readable description to the output
+func (d *Description) Print(out io.Writer) {
+	service := d.Service
+	fmt.Fprintf(out, "Name:            %s\n", service.Name)
+	fmt.Fprintf(out, "Namespace:       %s\n", service.Namespace)
+	fmt.Fprintf(out, "URL:             %s\n", service.Status.URL)
+	fmt.Fprintf(out, "Age:             %s\n", age(service.CreationTimestamp))
+	fmt.Fprintf(out, "Image:           %s\n", serviceImage(service))
+	concurrency := "unlimited"
+	if c := service.Spec.Template.Spec.ContainerConcurrency; c != nil && *c != 0 {
+		concurrency = strconv.FormatInt(*c, 10)
+	}
+	fmt.Fprintf(out, "Concurrency:     %s\n", concurrency)
+	if account := service.Spec.Template.Spec.ServiceAccountName; account != "" {
+		fmt.Fprintf(out, "Service Account: %s\n", account)
+	}
+	if containers := service.Spec.Template.Spec.Containers; len(containers) != 0 && len(containers[0].Env) != 0 {
+		var env []string
+		for _, v := range containers[0].Env {
+			env = append(env, v.Name)
+		}
+		fmt.Fprintf(out, "Env:             %s\n", strings.Join(env, ", "))
+	}
+	fmt.Fprintf(out, "Ready:           %s\n", conditionSummary(service.Status.GetCondition(apis.ConditionReady)))
+
+	printSection(out, "Revisions", d.revisionsTable())
+	printSection(out, "Routes", d.routesTable())
+	printSection(out, "PingSources", d.pingSourcesTable())
+	printSection(out, "Triggers", d.triggersTable())
+	printSection(out, "Build", d.buildTable())
+	printSection(out, "Events", d.eventsTable())
+}
+
+func (d *Description) revisionsTable() printer.Table {
+	traffic := map[string]int64{}
+	tags := map[string][]string{}
+	for _, target := range d.Service.Status.Traffic {
+		revision := target.RevisionName
+		if revision == "" && target.LatestRevision != nil && *target.LatestRevision {
+			revision = d.Service.Status.LatestReadyRevisionName
+		}
+		if target.Percent != nil {
+			traffic[revision] += *target.Percent
+		}
+		if target.Tag != "" {
+			tags[revision] = append(tags[revision], target.Tag)
+		}
+	}
+	table := printer.Table{
+		Headers: []string{"Name", "Traffic", "Tags", "Ready", "Age"},
+		Rows:    make([][]string, 0, len(d.Revisions)),
+	}
+	for _, revision := range d.Revisions {
+		percent := ""
+		if p, ok := traffic[revision.Name]; ok {
+			percent = fmt.Sprintf("%d%%", p)
+		}
+		table.Rows = append(table.Rows, []string{
+			revision.Name,
+			percent,
+			strings.Join(tags[revision.Name], ","),
+			readyStatus(revision.Status.Status),
+			age(revision.CreationTimestamp),
+		})
+	}
+	return table
+}
+
+func (d *Description) routesTable() printer.Table {
+	table := printer.Table{
+		Headers: []string{"URL", "Tag", "Revision", "Traffic"},
+	}
+	if d.Service.Status.URL != nil {
+		table.Rows = append(table.Rows, []string{d.Service.Status.URL.String(), "", "", ""})
+	}
+	for _, target := range d.Service.Status.Traffic {
+		if target.Tag == "" || target.URL == nil {
+			continue
+		}
+		percent := ""
+		if target.Percent != nil {
+			percent = fmt.Sprintf("%d%%", *target.Percent)
+		}
+		table.Rows = append(table.Rows, []string{target.URL.String(), target.Tag, target.RevisionName, percent})
+	}
+	return table
+}
+
+func (d *Description) pingSourcesTable() printer.Table {
+	table := printer.Table{
+		Headers: []string{"Name", "Schedule", "Data", "Ready"},
+		Rows:    make([][]string, 0, len(d.PingSources)),
+	}
+	for _, ps := range d.PingSources {
+		table.Rows = append(table.Rows, []string{ps.Name, ps.Spec.Schedule, ps.Spec.Data, readyStatus(ps.Status.Status)})
+	}
+	return table
+}
+
+func (d *Description) triggersTable() printer.Table {
+	table := printer.Table{
+		Headers: []string{"Name", "Broker", "Filter", "Ready"},
+		Rows:    make([][]string, 0, len(d.Triggers)),
+	}
+	for _, trigger := range d.Triggers {
+		var filter []string
+		if trigger.Spec.Filter != nil {
+			for k, v := range trigger.Spec.Filter.Attributes {
+				filter = append(filter, k+"="+v)
+			}
+			sort.Strings(filter)
+		}
+		table.Rows = append(table.Rows, []string{trigger.Name, trigger.Spec.Broker, strings.Join(filter, ","), readyStatus(trigger.Status.Status)})
+	}
+	return table
+}
+
+func (d *Description) buildTable() printer.Table {
+	table := printer.Table{
+		Headers: []string{"TaskRun", "Succeeded", "Reason", "Age", "Duration"},
+	}
+	if d.Build == nil {
+		return table
+	}
+	succeeded, reason := "Unknown", ""
+	if c := d.Build.Status.GetCondition(apis.ConditionSucceeded); c != nil {
+		succeeded, reason = string(c.Status), c.Reason
+	}
+	took := ""
+	if start, end := d.Build.Status.StartTime, d.Build.Status.CompletionTime; start != nil && end != nil {
+		took = end.Sub(start.Time).Round(time.Second).String()
+	}
+	table.Rows = append(table.Rows, []string{d.Build.Name, succeeded, reason, age(d.Build.CreationTimestamp), took})
+	return table
+}
+
+func (d *Description) eventsTable() printer.Table {
+	table := printer.Table{
+		Headers: []string{"Last Seen", "Type", "Reason", "Object", "Message"},
+		Rows:    make([][]string, 0, len(d.Events)),
+	}
+	for _, event := range d.Events {
+		table.Rows = append(table.Rows, []string{
+			duration.HumanDuration(time.Since(eventTime(event))),
+			event.Type,
+			event.Reason,
+			strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name,
+			strings.TrimSpace(event.Message),
+		})
+	}
+	return table
+}
+
+func printSection(out io.Writer, title string, table printer.Table) {
+	if len(table.Rows) == 0 {
+		fmt.Fprintf(out, "\n%s: <none>\n", title)
+		return
+	}
+	fmt.Fprintf(out, "\n%s:\n", title)
+	printer.NewPrinter(out).PrintTable(table)
+}
+
+func readyStatus(status duckv1.Status) string {
+	return conditionSummary(status.GetCondition(apis.ConditionReady))
+}
+
+func conditionSummary(condition *apis.Condition) string {
+	if condition == nil {
+		return string(corev1.ConditionUnknown)
+	}
+	if condition.Reason != "" && !condition.IsTrue() {
+		return fmt.Sprintf("%s (%s)", condition.Status, condition.Reason)
+	}
+	return string(condition.Status)
+}
+
+func age(timestamp metav1.Time) string {
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	"github.com/triggermesh/tm/pkg/resources/task"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestDescribe(t *testing.T) {
+	namespace := "test-namespace"
+	now := time.Now()
+	meta := func(name, uid string, age time.Duration, owner *servingv1.Service) metav1.ObjectMeta {
+		m := metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			UID:               types.UID(uid),
+			CreationTimestamp: metav1.Time{Time: now.Add(-age)},
+			Labels:            map[string]string{"serving.knative.dev/service": "foo"},
+		}
+		if owner != nil {
+			m.OwnerReferences = []metav1.OwnerReference{{Kind: "Service", Name: owner.Name, UID: owner.UID}}
+		}
+		return m
+	}
+	image := "registry/test-namespace/foo:abc123"
+	latest := true
+	percent := func(p int64) *int64 { return &p }
+
+	ksvc := &servingv1.Service{ObjectMeta: meta("foo", "svc-uid", time.Hour, nil)}
+	ksvc.Spec.Template.Spec.Containers = []corev1.Container{{Image: image}}
+	ksvc.Status.URL = apis.HTTP("foo.test-namespace.example.com")
+	ksvc.Status.LatestReadyRevisionName = "foo-00002"
+	ksvc.Status.Traffic = []servingv1.TrafficTarget{
+		{LatestRevision: &latest, Percent: percent(90)},
+		{RevisionName: "foo-00001", Tag: "old", Percent: percent(10), URL: apis.HTTP("old-foo.test-namespace.example.com")},
+	}
+
+	revision := func(name, uid string, age time.Duration) *servingv1.Revision {
+		return &servingv1.Revision{ObjectMeta: meta(name, uid, age, ksvc)}
+	}
+	build := func(name, image string, age time.Duration) *v1beta1.TaskRun {
+		tr := &v1beta1.TaskRun{ObjectMeta: meta(name, name, age, ksvc)}
+		task.SetBuildLabel(&tr.ObjectMeta)
+		tr.Spec.Params = []v1beta1.Param{{Name: "IMAGE", Value: *v1beta1.NewArrayOrString(image)}}
+		return tr
+	}
+	event := func(name, uid, reason string, age time.Duration) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: namespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Revision", Name: name, UID: types.UID(uid)},
+			Reason:         reason,
+			LastTimestamp:  metav1.Time{Time: now.Add(-age)},
+		}
+	}
+	trigger := &eventingv1.Trigger{ObjectMeta: meta("foo-trigger", "trigger-uid", time.Hour, nil)}
+	trigger.Spec.Broker = "default"
+	trigger.Spec.Subscriber = duckv1.Destination{Ref: &duckv1.KReference{APIVersion: "serving.knative.dev/v1", Kind: "Service", Name: "foo"}}
+
+	unlabeled := build("foo-unlabeled", image, time.Second)
+	unlabeled.Labels = nil
+
+	clientset := fake.NewClient(
+		ksvc,
+		revision("foo-00001", "rev1-uid", time.Hour),
+		revision("foo-00002", "rev2-uid", time.Minute),
+		&sourcesv1.PingSource{ObjectMeta: meta("foo-ping", "ping-uid", time.Hour, ksvc), Spec: sourcesv1.PingSourceSpec{Schedule: "*/1 * * * *"}},
+		&sourcesv1.PingSource{ObjectMeta: meta("bar-ping", "bar-uid", time.Hour, nil)},
+		trigger,
+		build("foo-build-old", "registry/test-namespace/foo:old", time.Hour),
+		build("foo-build", image, time.Minute),
+		unlabeled,
+		event("foo-00002", "rev2-uid", "Ready", time.Minute),
+		event("foo-00001", "rev1-uid", "Created", time.Hour),
+		event("bar", "bar-uid", "Unrelated", time.Minute),
+	)
+
+	s := &Service{Name: "foo", Namespace: namespace}
+	d, err := s.Describe(&clientset)
+	assert.NoError(t, err)
+	assert.Len(t, d.Revisions, 2)
+	assert.Equal(t, "foo-00002", d.Revisions[0].Name)
+	assert.Len(t, d.PingSources, 1)
+	assert.Equal(t, "foo-ping", d.PingSources[0].Name)
+	assert.Len(t, d.Triggers, 1)
+	assert.Equal(t, "foo-build", d.Build.Name)
+	assert.Len(t, d.Events, 2)
+	assert.Equal(t, "Created", d.Events[0].Reason)
+
+	var out bytes.Buffer
+	d.Print(&out)
+	var lines []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	for _, expected := range []string{
+		"foo-00002 90% Unknown 60s",
+		"foo-00001 10% old Unknown 60m",
+		"http://old-foo.test-namespace.example.com old foo-00001 10%",
+		"foo-ping */1 * * * * Unknown",
+		"foo-trigger default Unknown",
+		"foo-build Unknown 60s",
+		"60s Ready revision/foo-00002",
+	} {
+		assert.Contains(t, lines, expected)
+	}
+}
@@ -15,14 +15,15 @@
 package service
 
 import (
-	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/triggermesh/tm/pkg/client"
 	"github.com/triggermesh/tm/pkg/printer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/watch"
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 )
 
@@ -37,6 +38,11 @@ func (s *Service) GetTable(list *servingv1.ServiceList) printer.Table {
 			"Ready",
 			"Reason",
 		},
+		WideHeaders: []string{
+			"Latest Revision",
+			"Image",
+			"Concurrency",
+		},
 		Rows: make([][]string, 0, len(list.Items)),
 	}
 
@@ -50,7 +56,6 @@ func (s *Service) row(item *servingv1.Service) []string {
 	name := item.Name
 	namespace := item.Namespace
 	url := item.Status.URL.String()
-	// lastestRevision := item.Status.ConfigurationStatusFields.LatestReadyRevisionName
 	age := duration.HumanDuration(time.Since(item.GetCreationTimestamp().Time))
 	ready := fmt.Sprintf("%v", item.IsReady())
 	readyCondition := item.Status.GetCondition(servingv1.ServiceConditionReady)
@@ -58,15 +63,26 @@ func (s *Service) row(item *servingv1.Service) []string {
 	if readyCondition != nil {
 		reason = readyCondition.Reason
 	}
+	latestRevision := item.Status.LatestReadyRevisionName
+	image := ""
+	if containers := item.Spec.Template.Spec.Containers; len(containers) != 0 {
+		image = containers[0].Image
+	}
+	concurrency := ""
+	if c := item.Spec.Template.Spec.ContainerConcurrency; c != nil {
+		concurrency = strconv.FormatInt(*c, 10)
+	}
 
 	row := []string{
 		namespace,
 		name,
 		url,
-		// lastestRevision,
 		age,
 		ready,
 		reason,
+		latestRevision,
+		image,
+		concurrency,
 	}
 
 	return row
@@ -74,5 +90,20 @@ func (s *Service) row(item *servingv1.Service) []string {
 
 // List returns k8s list object
 func (s *Service) List(clientset *client.ConfigSet) (*servingv1.ServiceList, error) {
-	return clientset.Serving.ServingV1().Services(s.Namespace).List(context.Background(), metav1.ListOptions{})
+	list := &servingv1.ServiceList{}
+	err := client.ListPages(client.ListOptions(), func(opts metav1.ListOptions) (string, error) {
+		page, err := clientset.Serving.ServingV1().Services(s.Namespace).List(clientset.Context, opts)
+		if err != nil {
+			return "", err
+		}
+		list.Items = append(list.Items, page.Items...)
+		list.ResourceVersion = page.ResourceVersion
+		return page.Continue, nil
+	})
+	return list, err
+}
+
+// Watch opens watch of services changes
+func (s *Service) Watch(clientset *client.ConfigSet, opts metav1.ListOptions) (watch.Interface, error) {
+	return clientset.Serving.ServingV1().Services(s.Namespace).Watch(clientset.Context, opts)
 }
@@ -15,7 +15,6 @@
 package service
 
 import (
-	"context"
 	"fmt"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -59,7 +58,7 @@ func (s *Service) pingSource(schedule, data string, owner kmeta.OwnerRefable) *s
 }
 
 func (s *Service) createPingSource(ps *sourcesv1.PingSource, clientset *client.ConfigSet) error {
-	_, err := clientset.Eventing.SourcesV1().PingSources(ps.Namespace).Create(context.Background(), ps, metav1.CreateOptions{})
+	_, err := clientset.Eventing.SourcesV1().PingSources(ps.Namespace).Create(clientset.Context, ps, metav1.CreateOptions{})
 	if err != nil {
 		return fmt.Errorf("cannot create PingSource %q: %w", ps.Name, err)
 	}
@@ -67,7 +66,7 @@ func (s *Service) createPingSource(ps *sourcesv1.PingSource, clientset *client.C
 }
 
 func (s *Service) removePingSources(uid types.UID, clientset *client.ConfigSet) error {
-	err := clientset.Eventing.SourcesV1().PingSources(s.Namespace).DeleteCollection(context.Background(), metav1.DeleteOptions{}, metav1.ListOptions{
+	err := clientset.Eventing.SourcesV1().PingSources(s.Namespace).DeleteCollection(clientset.Context, metav1.DeleteOptions{}, metav1.ListOptions{
 		LabelSelector: serviceLabelKey + "=" + s.Name,
 	})
 	if err != nil {
@@ -16,13 +16,21 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubernetesFake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
+	eventingFake "knative.dev/eventing/pkg/client/clientset/versioned/fake"
 
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
 	"github.com/triggermesh/tm/pkg/file"
 )
 
@@ -68,8 +76,15 @@ func TestPingSource(t *testing.T) {
 
 	client.Dry = false
 	client.Wait = false
-	serviceClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	serviceClient := fake.NewClient()
+	// eventing webhook rejects PingSources with invalid cron expression
+	serviceClient.Eventing.(*eventingFake.Clientset).PrependReactor("create", "pingsources", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ps := action.(k8stesting.CreateAction).GetObject().(*sourcesv1.PingSource)
+		if len(strings.Fields(ps.Spec.Schedule)) != 5 {
+			return true, nil, fmt.Errorf("admission webhook denied the request: invalid value: %s", ps.Spec.Schedule)
+		}
+		return false, nil, nil
+	})
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -104,3 +119,66 @@ func TestPingSource(t *testing.T) {
 		})
 	}
 }
+
+func TestPingSourceReconcile(t *testing.T) {
+	client.Dry = false
+	client.Wait = false
+	clientset := fake.NewClient()
+
+	s := &Service{
+		Name:      "scheduled",
+		Namespace: "test-namespace",
+		Source:    "gcr.io/google-samples/hello-app:1.0",
+		Schedule: []file.Schedule{
+			{Cron: "*/1 * * * *", JSONData: `{"some":"data"}`},
+			{Cron: "0 * * * *"},
+		},
+	}
+	list := func() []sourcesv1.PingSource {
+		psList, err := clientset.Eventing.SourcesV1().PingSources(s.Namespace).List(context.Background(), metav1.ListOptions{
+			LabelSelector: serviceLabelKey + "=" + s.Name,
+		})
+		assert.NoError(t, err)
+		return psList.Items
+	}
+
+	_, err := s.Deploy(&clientset)
+	assert.NoError(t, err)
+	assert.Len(t, list(), 2)
+
+	// redeployment replaces existing schedules with the manifest ones
+	s.Schedule = s.Schedule[1:]
+	_, err = s.Deploy(&clientset)
+	assert.NoError(t, err)
+	items := list()
+	assert.Len(t, items, 1)
+	assert.Equal(t, "0 * * * *", items[0].Spec.Schedule)
+	assert.Equal(t, s.Name, items[0].Spec.Sink.Ref.Name)
+	assert.Equal(t, s.Name, items[0].OwnerReferences[0].Name)
+
+	s.Schedule = nil
+	_, err = s.Deploy(&clientset)
+	assert.NoError(t, err)
+	assert.Empty(t, list())
+}
+
+func TestPingSourceWithoutEventing(t *testing.T) {
+	client.Dry = false
+	client.Wait = false
+	clientset := fake.NewClient()
+	clientset.Core.(*kubernetesFake.Clientset).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "serving.knative.dev/v1", APIResources: []metav1.APIResource{{Name: "services"}}},
+	}
+
+	s := &Service{
+		Name:      "scheduled",
+		Namespace: "test-namespace",
+		Source:    "gcr.io/google-samples/hello-app:1.0",
+	}
+	_, err := s.Deploy(&clientset)
+	assert.NoError(t, err)
+
+	s.Schedule = []file.Schedule{{Cron: "0 * * * *"}}
+	_, err = s.Deploy(&clientset)
+	assert.EqualError(t, err, "Knative Eventing is not installed in this cluster; install it or remove the schedule")
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+
+	"github.com/triggermesh/tm/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FunctionLabelKey marks ServiceAccount, Role and RoleBinding created by tm for the function
+const FunctionLabelKey = "cli.triggermesh.io/function"
+
+// serviceAccountName returns the account of the function pods,
+// dedicated account is named after the function unless the name is set explicitly
+func (s *Service) serviceAccountName() string {
+	if s.ServiceAccount == "" && len(s.Permissions) != 0 {
+		return s.Name
+	}
+	return s.ServiceAccount
+}
+
+// buildServiceAccountName returns the account of the function builds. Builds do not
+// need function permissions and run with the account tm does not manage
+func (s *Service) buildServiceAccountName() string {
+	if len(s.Permissions) != 0 {
+		return ""
+	}
+	return s.ServiceAccount
+}
+
+// applyServiceAccount creates or updates function ServiceAccount, Role and RoleBinding
+// if the function has permissions. Dedicated ServiceAccount inherits image pull secrets of the
+// default one. Builds do not use this account, so git and registry push credentials are not shared
+// with the function pods.
+func (s *Service) applyServiceAccount(clientset *client.ConfigSet) error {
+	if len(s.Permissions) == 0 || client.Dry {
+		return nil
+	}
+	ctx := clientset.Context
+	labels := map[string]string{FunctionLabelKey: s.Name}
+	account := s.serviceAccountName()
+
+	defaultSA := &corev1.ServiceAccount{}
+	if existing, err := clientset.Core.CoreV1().ServiceAccounts(s.Namespace).Get(ctx, "default", metav1.GetOptions{}); err == nil {
+		defaultSA = existing
+	}
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      account,
+			Namespace: s.Namespace,
+			Labels:    labels,
+		},
+		ImagePullSecrets: defaultSA.ImagePullSecrets,
+	}
+	_, err := clientset.Core.CoreV1().ServiceAccounts(s.Namespace).Create(ctx, sa, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		existing, err := clientset.Core.CoreV1().ServiceAccounts(s.Namespace).Get(ctx, account, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if existing.Labels[FunctionLabelKey] != s.Name {
+			return fmt.Errorf("service account %q exists and is not managed by tm, remove permissions or use another name", account)
+		}
+		// credentials might be added to the default account after the function account was created
+		mergeAccountSecrets(existing, defaultSA)
+		if _, err := clientset.Core.CoreV1().ServiceAccounts(s.Namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("updating service account: %s", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("creating service account: %s", err)
+	}
+
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.Name,
+			Namespace: s.Namespace,
+			Labels:    labels,
+		},
+		Rules: s.Permissions,
+	}
+	if _, err := clientset.Core.RbacV1().Roles(s.Namespace).Create(ctx, role, metav1.CreateOptions{}); k8serrors.IsAlreadyExists(err) {
+		existing, err := clientset.Core.RbacV1().Roles(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Rules = s.Permissions
+		if _, err := clientset.Core.RbacV1().Roles(s.Namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("updating role: %s", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("creating role: %s", err)
+	}
+
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.Name,
+			Namespace: s.Namespace,
+			Labels:    labels,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      account,
+				Namespace: s.Namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     s.Name,
+		},
+	}
+	if _, err := clientset.Core.RbacV1().RoleBindings(s.Namespace).Create(ctx, binding, metav1.CreateOptions{}); k8serrors.IsAlreadyExists(err) {
+		existing, err := clientset.Core.RbacV1().RoleBindings(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		// role reference is immutable, binding is re-created if it points to another role
+		if existing.RoleRef != binding.RoleRef {
+			if err := clientset.Core.RbacV1().RoleBindings(s.Namespace).Delete(ctx, s.Name, metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+			_, err = clientset.Core.RbacV1().RoleBindings(s.Namespace).Create(ctx, binding, metav1.CreateOptions{})
+			return err
+		}
+		existing.Subjects = binding.Subjects
+		if _, err := clientset.Core.RbacV1().RoleBindings(s.Namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("updating role binding: %s", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("creating role binding: %s", err)
+	}
+	return nil
+}
+
+// deleteServiceAccount removes ServiceAccount, Role and RoleBinding created by tm for the function.
+// Account with keep name is not removed since the function still uses it.
+func (s *Service) deleteServiceAccount(clientset *client.ConfigSet, keep string) error {
+	ctx := clientset.Context
+	selector := metav1.ListOptions{LabelSelector: FunctionLabelKey + "=" + s.Name}
+	bindings, err := clientset.Core.RbacV1().RoleBindings(s.Namespace).List(ctx, selector)
+	if err != nil {
+		return err
+	}
+	for _, binding := range bindings.Items {
+		if err := clientset.Core.RbacV1().RoleBindings(s.Namespace).Delete(ctx, binding.Name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	roles, err := clientset.Core.RbacV1().Roles(s.Namespace).List(ctx, selector)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles.Items {
+		if err := clientset.Core.RbacV1().Roles(s.Namespace).Delete(ctx, role.Name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	accounts, err := clientset.Core.CoreV1().ServiceAccounts(s.Namespace).List(ctx, selector)
+	if err != nil {
+		return err
+	}
+	for _, sa := range accounts.Items {
+		if sa.Name == keep {
+			continue
+		}
+		if err := clientset.Core.CoreV1().ServiceAccounts(s.Namespace).Delete(ctx, sa.Name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeAccountSecrets adds image pull secrets of the source account missing in the target
+// and removes the target references to the source account secrets
+func mergeAccountSecrets(target, source *corev1.ServiceAccount) {
+	// secrets of the default account were copied by older versions
+	copied := make(map[string]bool)
+	for _, ref := range source.Secrets {
+		copied[ref.Name] = true
+	}
+	secrets := target.Secrets[:0]
+	for _, ref := range target.Secrets {
+		if !copied[ref.Name] {
+			secrets = append(secrets, ref)
+		}
+	}
+	target.Secrets = secrets
+	pullSecrets := make(map[string]bool)
+	for _, ref := range target.ImagePullSecrets {
+		pullSecrets[ref.Name] = true
+	}
+	for _, ref := range source.ImagePullSecrets {
+		if !pullSecrets[ref.Name] {
+			target.ImagePullSecrets = append(target.ImagePullSecrets, ref)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	"github.com/triggermesh/tm/pkg/file"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServiceAccountName(t *testing.T) {
+	permissions := []rbacv1.PolicyRule{{Resources: []string{"configmaps"}, Verbs: []string{"get"}}}
+	testCases := []struct {
+		name     string
+		service  Service
+		expected string
+		build    string
+	}{
+		{"namespace default", Service{Name: "foo-bar"}, "", ""},
+		{"existing account", Service{Name: "foo-bar", ServiceAccount: "reader"}, "reader", "reader"},
+		{"dedicated account", Service{Name: "foo-bar", Permissions: permissions}, "foo-bar", ""},
+		{"named dedicated account", Service{Name: "foo-bar", ServiceAccount: "reader", Permissions: permissions}, "reader", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.service.serviceAccountName())
+			assert.Equal(t, tc.build, tc.service.buildServiceAccountName())
+		})
+	}
+}
+
+func TestApplyServiceAccount(t *testing.T) {
+	client.Dry = false
+	client.Wait = false
+	clientset := fake.NewClient(&corev1.ServiceAccount{
+		ObjectMeta:       metav1.ObjectMeta{Name: "default", Namespace: "test-namespace"},
+		Secrets:          []corev1.ObjectReference{{Name: "default-token"}, {Name: "git-token-github-com"}},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+	})
+	ctx := context.Background()
+
+	s := &Service{
+		Name:        "reader",
+		Namespace:   "test-namespace",
+		Source:      "gcr.io/google-samples/hello-app:1.0",
+		Permissions: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}}},
+	}
+	_, err := s.Deploy(&clientset)
+	assert.NoError(t, err)
+	sa, err := clientset.Core.CoreV1().ServiceAccounts("test-namespace").Get(ctx, "reader", metav1.GetOptions{})
+	assert.NoError(t, err)
+	// function pods get only image pull credentials of the default account
+	assert.Empty(t, sa.Secrets)
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "registry"}}, sa.ImagePullSecrets)
+	_, err = clientset.Core.RbacV1().Roles("test-namespace").Get(ctx, "reader", metav1.GetOptions{})
+	assert.NoError(t, err)
+	_, err = clientset.Core.RbacV1().RoleBindings("test-namespace").Get(ctx, "reader", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// permissions removed from the manifest
+	s.Permissions = nil
+	_, err = s.Deploy(&clientset)
+	assert.NoError(t, err)
+	ksvc, err := s.Get(&clientset)
+	assert.NoError(t, err)
+	assert.Empty(t, ksvc.Spec.Template.Spec.ServiceAccountName)
+	_, err = clientset.Core.CoreV1().ServiceAccounts("test-namespace").Get(ctx, "reader", metav1.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err))
+	_, err = clientset.Core.RbacV1().Roles("test-namespace").Get(ctx, "reader", metav1.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err))
+	_, err = clientset.Core.RbacV1().RoleBindings("test-namespace").Get(ctx, "reader", metav1.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestServiceObjectAccount(t *testing.T) {
+	parent := Service{Name: "foo", ServiceAccount: "functions"}
+	service := parent.serviceObject(file.Function{})
+	assert.Equal(t, "functions", service.ServiceAccount)
+	assert.Empty(t, service.Permissions)
+
+	service = parent.serviceObject(file.Function{
+		ServiceAccount: "reader",
+		Permissions: []file.Permission{
+			{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list"}},
+		},
+	})
+	assert.Equal(t, "reader", service.ServiceAccount)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list"}},
+	}, service.Permissions)
+}
+
+func TestMergeAccountSecrets(t *testing.T) {
+	target := &corev1.ServiceAccount{
+		Secrets: []corev1.ObjectReference{{Name: "foo-token"}, {Name: "git-ssh-github-com"}},
+	}
+	source := &corev1.ServiceAccount{
+		Secrets:          []corev1.ObjectReference{{Name: "git-ssh-github-com"}, {Name: "git-token-gitlab-com"}},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+	}
+	mergeAccountSecrets(target, source)
+	assert.Equal(t, []corev1.ObjectReference{{Name: "foo-token"}}, target.Secrets)
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "registry"}}, target.ImagePullSecrets)
+}
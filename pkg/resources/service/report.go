@@ -0,0 +1,139 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/taskrun"
+)
+
+// BuildReport is a machine-readable result of the manifest functions build
+type BuildReport struct {
+	Service   string        `json:"service"`
+	Functions []BuildResult `json:"functions"`
+}
+
+// BuildResult describes a single function image build
+type BuildResult struct {
+	Function string `json:"function"`
+	Image    string `json:"image,omitempty"`
+	Digest   string `json:"digest,omitempty"`
+	TaskRun  string `json:"taskrun,omitempty"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// BuildYAML builds images of the functions defined in YAML manifest without deploying them
+// and returns the build report. If some of the builds failed, the report is returned along with error.
+func (s *Service) BuildYAML(yamlFile string, functionsToBuild []string, threads int, clientset *client.ConfigSet) (BuildReport, error) {
+	report := BuildReport{}
+	services, err := s.ManifestToServices(yamlFile)
+	if err != nil {
+		return report, err
+	}
+	report.Service = s.Name
+
+	var functions []Service
+	for _, service := range services {
+		if s.inList(service.Name, functionsToBuild) {
+			service.BuildOnly = true
+			functions = append(functions, service)
+		}
+	}
+
+	var failed int
+	for _, d := range s.runPipeline(functions, threads, clientset) {
+		result := BuildResult{
+			Function: d.service.Name,
+			Image:    d.image,
+			Duration: d.buildTime.Round(time.Second).String(),
+		}
+		if tr, ok := d.builder.(*taskrun.TaskRun); ok {
+			result.TaskRun = tr.Name
+			result.Digest = tr.Digest
+		}
+		if d.Error != nil {
+			result.Error = d.Error.Error()
+			failed++
+		}
+		report.Functions = append(report.Functions, result)
+	}
+
+	if failed != 0 {
+		return report, fmt.Errorf("There were errors during manifest build: %d of %d functions failed", failed, len(functions))
+	}
+	return report, nil
+}
+
+// Images returns successfully built function images pinned by digests when they are known
+func (r BuildReport) Images() map[string]string {
+	images := make(map[string]string, len(r.Functions))
+	for _, f := range r.Functions {
+		if f.Error != "" || f.Image == "" {
+			continue
+		}
+		images[f.Function] = f.PinnedImage()
+	}
+	return images
+}
+
+// PinnedImage returns image reference with digest instead of tag if digest is known
+func (r BuildResult) PinnedImage() string {
+	if r.Digest == "" {
+		return r.Image
+	}
+	repository := r.Image
+	if i := strings.LastIndex(repository, "@"); i != -1 {
+		repository = repository[:i]
+	}
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	return repository + "@" + r.Digest
+}
+
+// Write saves report to the file, "-" path prints report to stdout, which must
+// not be shared with the build progress Output
+func (r BuildReport) Write(path string, stdout io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		_, err = fmt.Fprintln(stdout, string(data))
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// ReadBuildReport reads build report from the file
+func ReadBuildReport(path string) (BuildReport, error) {
+	var report BuildReport
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return report, err
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("parsing build report: %s", err)
+	}
+	return report, nil
+}
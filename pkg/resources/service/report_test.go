@@ -0,0 +1,78 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+)
+
+func TestBuildReport(t *testing.T) {
+	digest := "sha256:0a6c5c8a2c4b0a4ad3bd1ec4bfa1cd2aa8a16b1e2d4e5cf6d0e6e8ee0a9c9d9e"
+	report := BuildReport{
+		Service: "foo",
+		Functions: []BuildResult{
+			{Function: "foo-tag", Image: "registry:5000/ns/foo-tag:abc123", Duration: "1m0s"},
+			{Function: "foo-digest", Image: "registry:5000/ns/foo-digest:abc123", Digest: digest, TaskRun: "foo-digest-x7k2p", Duration: "2m0s"},
+			{Function: "foo-failed", Image: "registry:5000/ns/foo-failed:abc123", Error: "build failed"},
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "tm-report")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "build-report.json")
+
+	require.NoError(t, report.Write(path, nil))
+	parsed, err := ReadBuildReport(path)
+	require.NoError(t, err)
+	assert.Equal(t, report, parsed)
+
+	assert.Equal(t, map[string]string{
+		"foo-tag":    "registry:5000/ns/foo-tag:abc123",
+		"foo-digest": "registry:5000/ns/foo-digest@" + digest,
+	}, parsed.Images())
+}
+
+func TestBuildReportStdout(t *testing.T) {
+	var progress, stdout bytes.Buffer
+	Output = &progress
+	defer func() { Output = os.Stdout }()
+
+	client.Dry = false
+	clientset := fake.NewClient()
+	s := &Service{Namespace: "test-namespace"}
+	report, err := s.BuildYAML("../../../testfiles/serverless-simple.yaml", nil, 1, &clientset)
+	require.NoError(t, err)
+	require.NoError(t, report.Write("-", &stdout))
+
+	var parsed BuildReport
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &parsed), stdout.String())
+	assert.Equal(t, report, parsed)
+	require.Len(t, parsed.Functions, 1)
+	assert.Equal(t, "serverless-test-bar", parsed.Functions[0].Function)
+	// build progress and summary stay in the progress output
+	assert.Contains(t, progress.String(), "serverless-test-bar")
+}
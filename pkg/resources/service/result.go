@@ -0,0 +1,84 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Function result statuses
+const (
+	StatusDeployed = "deployed"
+	// StatusStarted is set when service is created without waiting for its ready state
+	StatusStarted = "started"
+	StatusBuilt   = "built"
+	StatusDeleted = "deleted"
+	// StatusRemoved is set for orphaned functions removed after manifest deployment
+	StatusRemoved = "removed"
+	StatusFailed  = "failed"
+)
+
+// FunctionResult is a machine-readable outcome of the manifest function deployment or deletion
+type FunctionResult struct {
+	Function string `json:"function"`
+	Status   string `json:"status"`
+	URL      string `json:"url,omitempty"`
+	Image    string `json:"image,omitempty"`
+	Revision string `json:"revision,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// WriteResults prints results as JSON objects, one per line
+func WriteResults(out io.Writer, results []FunctionResult) error {
+	encoder := json.NewEncoder(out)
+	for _, result := range results {
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *deployment) result() FunctionResult {
+	result := FunctionResult{
+		Function: d.service.Name,
+		Status:   StatusStarted,
+		Image:    d.image,
+		Duration: d.task.Elapsed().Round(time.Second).String(),
+	}
+	switch {
+	case d.Error != nil:
+		result.Status = StatusFailed
+		result.Error = d.Error.Error()
+	case d.service.BuildOnly:
+		result.Status = StatusBuilt
+	}
+	if ksvc := d.service.deployed; ksvc != nil {
+		if ksvc.IsReady() && d.Error == nil {
+			result.Status = StatusDeployed
+		}
+		if ksvc.Status.URL != nil {
+			result.URL = ksvc.Status.URL.String()
+		}
+		result.Revision = ksvc.Status.LatestReadyRevisionName
+		if result.Revision == "" {
+			result.Revision = ksvc.Status.LatestCreatedRevisionName
+		}
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestDeployFunctionsResults(t *testing.T) {
+	Output = ioutil.Discard
+	client.Dry = false
+	client.Wait = false
+	clientset := fake.NewClient(&servingv1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:      "project-orphan",
+		Namespace: "test-namespace",
+		Labels:    map[string]string{"service": "project"},
+	}})
+
+	s := &Service{Name: "project", Namespace: "test-namespace"}
+	results, err := s.DeployFunctions([]Service{{
+		Name:      "project-foo",
+		Namespace: "test-namespace",
+		Source:    "gcr.io/google-samples/hello-app:1.0",
+		Labels:    []string{"service:project"},
+	}}, true, 1, &clientset)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "project-foo", results[0].Function)
+	assert.Equal(t, StatusStarted, results[0].Status)
+	assert.Equal(t, "gcr.io/google-samples/hello-app:1.0", results[0].Image)
+	assert.Equal(t, FunctionResult{Function: "project-orphan", Status: StatusRemoved}, results[1])
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteResults(&out, results))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, `{"function":"project-orphan","status":"removed"}`, lines[1])
+}
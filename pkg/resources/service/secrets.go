@@ -0,0 +1,224 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/afero"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/sealed"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const redactedValue = "<redacted>"
+
+// manifestSecret is a secret declared in the manifest, its values are read only when secret is applied
+type manifestSecret struct {
+	name    string
+	source  file.Secret
+	workdir string
+}
+
+// addSecrets registers manifest secrets, relative paths are resolved against workdir
+func (s *Service) addSecrets(secrets map[string]file.Secret, workdir string) {
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s.secrets = append(s.secrets, manifestSecret{
+			name:    name,
+			source:  secrets[name],
+			workdir: workdir,
+		})
+	}
+}
+
+// secretObjects reads manifest secrets values from local files, .env files and environment
+func (s *Service) secretObjects() ([]corev1.Secret, error) {
+	result := make([]corev1.Secret, 0, len(s.secrets))
+	for _, secret := range s.secrets {
+		data, err := s.secretData(secret.source, secret.workdir)
+		if err != nil {
+			return nil, fmt.Errorf("secret %q: %s", secret.name, err)
+		}
+		result = append(result, corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Secret",
+				APIVersion: "v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secret.name,
+				Namespace: s.Namespace,
+				Labels: map[string]string{
+					"service": s.Name,
+				},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: data,
+		})
+	}
+	return result, nil
+}
+
+// secretData collects secret values from all sources and decrypts encrypted ones
+func (s *Service) secretData(secret file.Secret, workdir string) (map[string][]byte, error) {
+	data := make(map[string][]byte)
+	for k, v := range secret.Data {
+		data[k] = []byte(v)
+	}
+	if secret.EnvFile != "" {
+		env, err := file.ParseEnvFile(localPath(secret.EnvFile, workdir))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range env {
+			data[k] = []byte(v)
+		}
+	}
+	for _, variable := range secret.Env {
+		key, name := variable, variable
+		if kv := strings.SplitN(variable, "=", 2); len(kv) == 2 {
+			key, name = kv[0], kv[1]
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %q is not set", name)
+		}
+		data[key] = []byte(value)
+	}
+	for key, filename := range secret.Files {
+		content, err := afero.ReadFile(file.Aos, localPath(filename, workdir))
+		if err != nil {
+			return nil, err
+		}
+		data[key] = content
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no values")
+	}
+	for k, v := range data {
+		// dry run output is redacted, decryption key is not required
+		if !sealed.IsEncrypted(string(v)) || client.Dry {
+			continue
+		}
+		if s.Decrypt == nil {
+			return nil, fmt.Errorf("key %q is encrypted, but decryption key is not configured", k)
+		}
+		value, err := s.Decrypt(string(v))
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %s", k, err)
+		}
+		data[k] = value
+	}
+	return data, nil
+}
+
+func localPath(filename, workdir string) string {
+	if filepath.IsAbs(filename) || workdir == "" {
+		return filename
+	}
+	return path.Join(workdir, filename)
+}
+
+// ApplySecrets creates or updates manifest secrets.
+// In dry run secrets are printed with redacted values.
+func (s *Service) ApplySecrets(clientset *client.ConfigSet) error {
+	secrets, err := s.secretObjects()
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets {
+		if client.Dry {
+			var obj []byte
+			if client.Output == "yaml" {
+				obj, err = yaml.Marshal(redact(secret))
+			} else {
+				obj, err = json.MarshalIndent(redact(secret), "", " ")
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(Output, string(obj))
+			continue
+		}
+		if err := createOrUpdateSecret(secret, clientset); err != nil {
+			return fmt.Errorf("secret %q: %s", secret.Name, err)
+		}
+		clientset.Log.Debugf("secret \"%s/%s\" is applied", secret.Namespace, secret.Name)
+	}
+	return nil
+}
+
+func createOrUpdateSecret(secret corev1.Secret, clientset *client.ConfigSet) error {
+	ctx := clientset.Context
+	_, err := clientset.Core.CoreV1().Secrets(secret.Namespace).Create(ctx, &secret, metav1.CreateOptions{})
+	if !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+	existing, err := clientset.Core.CoreV1().Secrets(secret.Namespace).Get(ctx, secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if existing.Labels["service"] != secret.Labels["service"] {
+		return fmt.Errorf("secret exists and does not belong to service %q", secret.Labels["service"])
+	}
+	existing.Data = secret.Data
+	_, err = clientset.Core.CoreV1().Secrets(secret.Namespace).Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+// deleteSecrets removes manifest secrets that belong to the service
+func (s *Service) deleteSecrets(clientset *client.ConfigSet) error {
+	ctx := clientset.Context
+	for _, secret := range s.secrets {
+		existing, err := clientset.Core.CoreV1().Secrets(s.Namespace).Get(ctx, secret.name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if existing.Labels["service"] != s.Name {
+			continue
+		}
+		if err := clientset.Core.CoreV1().Secrets(s.Namespace).Delete(ctx, secret.name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("secret %q: %s", secret.name, err)
+		}
+	}
+	return nil
+}
+
+// redact returns secret copy with all values replaced
+func redact(secret corev1.Secret) corev1.Secret {
+	redacted := *secret.DeepCopy()
+	redacted.StringData = make(map[string]string, len(secret.Data))
+	for k := range secret.Data {
+		redacted.StringData[k] = redactedValue
+	}
+	redacted.Data = nil
+	return redacted
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/sealed"
+)
+
+func TestSecretObjects(t *testing.T) {
+	fs := file.Aos
+	defer func() { file.Aos = fs }()
+	file.Aos = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(file.Aos, "/project/.env", []byte("DB_USER=admin\nDB_PASSWORD=secret\n"), 0600))
+	require.NoError(t, afero.WriteFile(file.Aos, "/project/certs/ca.crt", []byte("certificate"), 0600))
+	os.Setenv("TM_TEST_API_TOKEN", "token")
+	defer os.Unsetenv("TM_TEST_API_TOKEN")
+
+	s := Service{Name: "foo", Namespace: "bar"}
+	s.addSecrets(map[string]file.Secret{
+		"db": {EnvFile: ".env"},
+		"api": {
+			Env:   []string{"TOKEN=TM_TEST_API_TOKEN"},
+			Files: map[string]string{"ca.crt": "certs/ca.crt"},
+		},
+	}, "/project")
+
+	secrets, err := s.secretObjects()
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+
+	assert.Equal(t, "api", secrets[0].Name)
+	assert.Equal(t, "bar", secrets[0].Namespace)
+	assert.Equal(t, "foo", secrets[0].Labels["service"])
+	assert.Equal(t, map[string][]byte{
+		"TOKEN":  []byte("token"),
+		"ca.crt": []byte("certificate"),
+	}, secrets[0].Data)
+
+	assert.Equal(t, "db", secrets[1].Name)
+	assert.Equal(t, []byte("secret"), secrets[1].Data["DB_PASSWORD"])
+
+	redacted := redact(secrets[1])
+	assert.Nil(t, redacted.Data)
+	assert.Equal(t, map[string]string{"DB_USER": redactedValue, "DB_PASSWORD": redactedValue}, redacted.StringData)
+	assert.Equal(t, []byte("secret"), secrets[1].Data["DB_PASSWORD"], "original secret must not be changed")
+
+	s.addSecrets(map[string]file.Secret{
+		"missing": {Env: []string{"TM_TEST_UNSET_VARIABLE"}},
+	}, "/project")
+	_, err = s.secretObjects()
+	assert.EqualError(t, err, `secret "missing": environment variable "TM_TEST_UNSET_VARIABLE" is not set`)
+}
+
+func TestSetupSecretMounts(t *testing.T) {
+	s := Service{SecretMounts: map[string]string{"tls.certs": "/etc/certs", "api": "/var/api"}}
+	volumes, mounts := s.setupSecretMounts()
+	require.Len(t, volumes, 2)
+	require.Len(t, mounts, 2)
+	assert.Equal(t, "secret-api", volumes[0].Name)
+	assert.Equal(t, "api", volumes[0].Secret.SecretName)
+	assert.Equal(t, "secret-tls-certs", mounts[1].Name)
+	assert.Equal(t, "/etc/certs", mounts[1].MountPath)
+	assert.True(t, mounts[1].ReadOnly)
+}
+
+func TestSecretDecryption(t *testing.T) {
+	key, err := sealed.GenerateKey()
+	require.NoError(t, err)
+	encrypted, err := key.Encrypt([]byte("s3cr3t"))
+	require.NoError(t, err)
+
+	s := Service{Name: "foo"}
+	s.addSecrets(map[string]file.Secret{
+		"api": {Data: map[string]string{"TOKEN": encrypted, "USER": "admin"}},
+	}, "")
+
+	_, err = s.secretObjects()
+	assert.EqualError(t, err, `secret "api": key "TOKEN" is encrypted, but decryption key is not configured`)
+
+	s.Decrypt = key.Decrypt
+	secrets, err := s.secretObjects()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"TOKEN": []byte("s3cr3t"),
+		"USER":  []byte("admin"),
+	}, secrets[0].Data)
+}
@@ -15,94 +15,56 @@
 package service
 
 import (
-	"fmt"
-	"net"
-	"net/url"
+	"context"
+	"io/ioutil"
 	"os"
-	"strings"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	k8stesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	servingFake "knative.dev/serving/pkg/client/clientset/versioned/fake"
 )
 
-// timeout in seconds to check that resulting service is reachable
-const dialTimeout = 10 * time.Second
-
 func TestDeployAndDelete(t *testing.T) {
-	namespace := "test-namespace"
-	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
-		namespace = ns
-	}
+	client.Dry = false
+	client.Wait = false
+	clientset := fake.NewClient()
 
-	testCases := []struct {
-		name    string
-		service *Service
-		wantErr bool
-	}{
-		{
-			name: "service from image",
-			service: &Service{
-				Name:   "test-service-from-image",
-				Source: "gcr.io/google-samples/hello-app:1.0",
-			},
-		}, {
-			name: "image not found",
-			service: &Service{
-				Name:   "test-missing-source",
-				Source: "https://404",
-			},
-			wantErr: true,
-		}, {
-			name: "runtime not found",
-			service: &Service{
-				Name:    "test-missing-runtime",
-				Source:  "https://github.com/serverless/examples",
-				Runtime: "https://404",
-			},
-			wantErr: true,
-		},
+	s := &Service{
+		Name:      "foo",
+		Namespace: "test-namespace",
+		Source:    "gcr.io/google-samples/hello-app:1.0",
+		Env:       []string{"FOO:bar"},
 	}
+	_, err := s.Deploy(&clientset)
+	assert.NoError(t, err)
 
-	client.Dry = false
-	client.Wait = true
-	client.Debug = true
-	serviceClient, err := client.NewClient(client.ConfigPath(""))
+	ksvc, err := s.Get(&clientset)
 	assert.NoError(t, err)
+	container := ksvc.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, s.Source, container.Image)
+	assert.Equal(t, "FOO", container.Env[0].Name)
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			tc.service.Namespace = namespace
-			output, err := tc.service.Deploy(&serviceClient)
-			if tc.wantErr {
-				assert.Error(t, err)
-				// some of the failed cases may leave kservices
-				// so we'll try remove them
-				tc.service.Delete(&serviceClient)
-				return
-			}
-
-			address := strings.TrimPrefix(output, fmt.Sprintf("Service %s URL: ", tc.service.Name))
-			addr, err := url.Parse(address)
-			assert.NoError(t, err)
-			switch addr.Scheme {
-			case "https":
-				address = addr.Host + ":443"
-			case "http":
-				address = addr.Host + ":80"
-			default:
-				t.Error("malformed service URL scheme", addr.Scheme)
-				return
-			}
-			conn, err := net.DialTimeout("tcp", address, dialTimeout)
-			assert.NoError(t, err)
-			assert.NoError(t, conn.Close())
-
-			err = tc.service.Delete(&serviceClient)
-			assert.NoError(t, err)
-		})
-	}
+	// second deployment updates existing service
+	s.Source = "gcr.io/google-samples/hello-app:2.0"
+	_, err = s.Deploy(&clientset)
+	assert.NoError(t, err)
+	list, err := s.List(&clientset)
+	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
+	assert.Equal(t, s.Source, list.Items[0].Spec.Template.Spec.Containers[0].Image)
+
+	assert.NoError(t, s.Delete(&clientset))
+	_, err = s.Get(&clientset)
+	assert.Error(t, err)
 }
 
 func TestList(t *testing.T) {
@@ -110,13 +72,13 @@ func TestList(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	serviceClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	serviceClient := fake.NewClient(&servingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: namespace}})
 
 	s := &Service{Name: "foo", Namespace: namespace}
 
-	_, err = s.List(&serviceClient)
+	list, err := s.List(&serviceClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
@@ -124,11 +86,59 @@ func TestGet(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	serviceClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	serviceClient := fake.NewClient()
 
 	s := &Service{Name: "foo", Namespace: namespace}
-	result, err := s.Get(&serviceClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	_, err := s.Get(&serviceClient)
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestRemoveOrphans(t *testing.T) {
+	Output = ioutil.Discard
+	client.Dry = false
+	ksvc := func(name, manifest string) *servingv1.Service {
+		return &servingv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-namespace",
+				Labels:    map[string]string{"service": manifest},
+			},
+		}
+	}
+	clientset := fake.NewClient(
+		ksvc("foo-kept", "foo"),
+		ksvc("foo-orphan", "foo"),
+		ksvc("bar-function", "bar"),
+	)
+
+	s := &Service{Name: "foo", Namespace: "test-namespace"}
+	removed, err := s.removeOrphans([]Service{{Name: "foo-kept"}}, &clientset)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo-orphan"}, removed)
+
+	list, err := clientset.Serving.ServingV1().Services("test-namespace").List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	assert.ElementsMatch(t, []string{"foo-kept", "bar-function"}, names)
+}
+
+func TestWait(t *testing.T) {
+	ready := &servingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test-namespace"}}
+	ready.Status.URL = apis.HTTP("foo.test-namespace.example.com")
+	ready.Status.SetConditions(apis.Conditions{{Type: apis.ConditionReady, Status: corev1.ConditionTrue}})
+
+	clientset := fake.NewClient()
+	clientset.Serving.(*servingFake.Clientset).PrependWatchReactor("services", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Modify(ready)
+		return true, w, nil
+	})
+
+	s := &Service{Name: "foo", Namespace: "test-namespace", BuildTimeout: "5s"}
+	service, err := s.wait(&clientset)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://foo.test-namespace.example.com", service.Status.URL.String())
 }
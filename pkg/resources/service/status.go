@@ -0,0 +1,190 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/printer"
+	"github.com/triggermesh/tm/pkg/resources/task"
+)
+
+// Drift between the manifest and the cluster
+const (
+	DriftMissing  = "not deployed"
+	DriftOrphaned = "not in manifest"
+)
+
+// FunctionStatus is the state of the manifest function in the cluster
+type FunctionStatus struct {
+	Name      string   `json:"name"`
+	Ready     string   `json:"ready,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Revision  string   `json:"revision,omitempty"`
+	Image     string   `json:"image,omitempty"`
+	Schedules []string `json:"schedules,omitempty"`
+	Build     string   `json:"build,omitempty"`
+	Drift     string   `json:"drift,omitempty"`
+}
+
+// Status returns the state of the manifest functions and of the services
+// that were deployed from the manifest but no longer defined in it.
+// Service must be initialized with the manifest by ManifestToServices
+func (s *Service) Status(functions []Service, clientset *client.ConfigSet) ([]FunctionStatus, error) {
+	list, err := clientset.Serving.ServingV1().Services(s.Namespace).List(clientset.Context, metav1.ListOptions{
+		LabelSelector: "service=" + s.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing services: %s", err)
+	}
+	schedules, err := s.schedules(clientset)
+	if err != nil {
+		return nil, err
+	}
+	builds, err := s.lastBuilds(clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	deployed := make(map[string]*servingv1.Service, len(list.Items))
+	for i, ksvc := range list.Items {
+		deployed[ksvc.Name] = &list.Items[i]
+	}
+	var result []FunctionStatus
+	for _, function := range functions {
+		ksvc, ok := deployed[function.Name]
+		if !ok {
+			status := FunctionStatus{Name: function.Name, Drift: DriftMissing}
+			for _, schedule := range function.Schedule {
+				status.Schedules = append(status.Schedules, schedule.Cron)
+			}
+			result = append(result, status)
+			continue
+		}
+		delete(deployed, function.Name)
+		result = append(result, functionStatus(ksvc, schedules, builds))
+	}
+	for _, ksvc := range deployed {
+		status := functionStatus(ksvc, schedules, builds)
+		status.Drift = DriftOrphaned
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func functionStatus(ksvc *servingv1.Service, schedules map[string][]string, builds map[types.UID]*v1beta1.TaskRun) FunctionStatus {
+	status := FunctionStatus{
+		Name:      ksvc.Name,
+		Ready:     conditionSummary(ksvc.Status.GetCondition(apis.ConditionReady)),
+		Revision:  ksvc.Status.LatestReadyRevisionName,
+		Image:     serviceImage(ksvc),
+		Schedules: schedules[ksvc.Name],
+	}
+	if ksvc.Status.URL != nil {
+		status.URL = ksvc.Status.URL.String()
+	}
+	if build, ok := builds[ksvc.UID]; ok {
+		status.Build = buildResult(build)
+	}
+	return status
+}
+
+// schedules returns PingSources schedules of the manifest services
+func (s *Service) schedules(clientset *client.ConfigSet) (map[string][]string, error) {
+	result := make(map[string][]string)
+	if ok, err := clientset.Has(client.KnativeSources); err != nil || !ok {
+		return result, err
+	}
+	list, err := clientset.Eventing.SourcesV1().PingSources(s.Namespace).List(clientset.Context, metav1.ListOptions{
+		LabelSelector: serviceLabelKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing PingSources: %s", err)
+	}
+	for _, ps := range list.Items {
+		name := ps.Labels[serviceLabelKey]
+		result[name] = append(result[name], ps.Spec.Schedule)
+	}
+	return result, nil
+}
+
+// lastBuilds returns the latest TaskRun of every service by the service UID
+func (s *Service) lastBuilds(clientset *client.ConfigSet) (map[types.UID]*v1beta1.TaskRun, error) {
+	result := make(map[types.UID]*v1beta1.TaskRun)
+	if ok, err := clientset.Has(client.TektonPipelines); err != nil || !ok {
+		return result, err
+	}
+	list, err := clientset.TektonTasks.TektonV1beta1().TaskRuns(s.Namespace).List(clientset.Context, metav1.ListOptions{
+		LabelSelector: task.BuildLabelKey + "=true",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing taskruns: %s", err)
+	}
+	for i, tr := range list.Items {
+		for _, owner := range tr.OwnerReferences {
+			if !isServiceOwner(owner) {
+				continue
+			}
+			if last, ok := result[owner.UID]; !ok || last.CreationTimestamp.Before(&tr.CreationTimestamp) {
+				result[owner.UID] = &list.Items[i]
+			}
+		}
+	}
+	return result, nil
+}
+
+func buildResult(tr *v1beta1.TaskRun) string {
+	condition := tr.Status.GetCondition(apis.ConditionSucceeded)
+	switch {
+	case condition == nil || condition.IsUnknown():
+		return tr.Name + " running"
+	case condition.IsTrue():
+		return tr.Name + " succeeded"
+	}
+	return fmt.Sprintf("%s failed: %s", tr.Name, condition.Reason)
+}
+
+// GetStatusTable converts functions status into printable table
+func (s *Service) GetStatusTable(functions []FunctionStatus) printer.Table {
+	table := printer.Table{
+		Headers: []string{"Function", "Ready", "Url", "Latest Revision", "Image", "Schedules", "Last Build", "Drift"},
+		Rows:    make([][]string, 0, len(functions)),
+	}
+	for _, f := range functions {
+		table.Rows = append(table.Rows, []string{
+			f.Name,
+			f.Ready,
+			f.URL,
+			f.Revision,
+			f.Image,
+			strings.Join(f.Schedules, ", "),
+			f.Build,
+			f.Drift,
+		})
+	}
+	return table
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/resources/task"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
+	"knative.dev/pkg/apis"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestStatus(t *testing.T) {
+	namespace := "test-namespace"
+	ksvc := func(name, uid string) *servingv1.Service {
+		svc := &servingv1.Service{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(uid),
+			Labels:    map[string]string{"service": "project"},
+		}}
+		svc.Spec.Template.Spec.Containers = []corev1.Container{{Image: "registry/" + name}}
+		svc.Status.LatestReadyRevisionName = name + "-00001"
+		svc.Status.SetConditions(apis.Conditions{{Type: apis.ConditionReady, Status: corev1.ConditionTrue}})
+		return svc
+	}
+	build := &v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{
+		Name:            "project-foo-build",
+		Namespace:       namespace,
+		Labels:          map[string]string{task.BuildLabelKey: "true"},
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "serving.knative.dev/v1", Kind: "Service", Name: "project-foo", UID: "foo-uid"}},
+	}}
+	build.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Reason: "Failed"})
+	// builds created by older versions reference services with Configuration kind
+	legacyBuild := &v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{
+		Name:            "project-removed-build",
+		Namespace:       namespace,
+		Labels:          map[string]string{task.BuildLabelKey: "true"},
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "serving.knative.dev/v1", Kind: "Configuration", Name: "project-removed", UID: "removed-uid"}},
+	}}
+	legacyBuild.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue})
+	// taskruns without build label are not created by tm builds
+	unlabeled := &v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{
+		Name:              "project-foo-other",
+		Namespace:         namespace,
+		CreationTimestamp: metav1.Now(),
+		OwnerReferences:   build.OwnerReferences,
+	}}
+	ping := &sourcesv1.PingSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "project-foo-ping", Namespace: namespace, Labels: map[string]string{serviceLabelKey: "project-foo"}},
+		Spec:       sourcesv1.PingSourceSpec{Schedule: "*/5 * * * *"},
+	}
+
+	clientset := fake.NewClient(
+		ksvc("project-foo", "foo-uid"),
+		ksvc("project-removed", "removed-uid"),
+		build,
+		legacyBuild,
+		unlabeled,
+		ping,
+	)
+
+	s := &Service{Name: "project", Namespace: namespace}
+	status, err := s.Status([]Service{
+		{Name: "project-foo"},
+		{Name: "project-new", Schedule: []file.Schedule{{Cron: "0 * * * *"}}},
+	}, &clientset)
+	assert.NoError(t, err)
+	assert.Equal(t, []FunctionStatus{
+		{
+			Name:      "project-foo",
+			Ready:     "True",
+			Revision:  "project-foo-00001",
+			Image:     "registry/project-foo",
+			Schedules: []string{"*/5 * * * *"},
+			Build:     "project-foo-build failed: Failed",
+		},
+		{
+			Name:      "project-new",
+			Schedules: []string{"0 * * * *"},
+			Drift:     DriftMissing,
+		},
+		{
+			Name:     "project-removed",
+			Ready:    "True",
+			Revision: "project-removed-00001",
+			Image:    "registry/project-removed",
+			Build:    "project-removed-build succeeded",
+			Drift:    DriftOrphaned,
+		},
+	}, status)
+}
@@ -14,13 +14,20 @@
 
 package service
 
-import "github.com/triggermesh/tm/pkg/file"
+import (
+	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/resources/cache"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	rbacv1 "k8s.io/api/rbac/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
 
 // Service represents knative service structure
 type Service struct {
 	Annotations    map[string]string
 	BuildArgs      []string
 	BuildTimeout   string
+	BuildRetries   int
 	BuildOnly      bool
 	Concurrency    int
 	Env            []string
@@ -34,6 +41,33 @@ type Service struct {
 	// Originally knative/buildtemplate, but now also tekton/task
 	Runtime string
 	Source  string
+	// Size of the volume claim to clone git sources into
+	WorkspaceSize string
+	// Image build cache storage
+	Cache cache.Cache
+	// Images maps function names to prebuilt images,
+	// functions from the manifest are deployed with these images without build
+	Images map[string]string
+	// OnPhase is called when service deployment moves to the next phase
+	OnPhase func(phase string)
+	// Number of parallel image builds and service deployments
+	// used in manifest deployment
+	BuildConcurrency  int
+	DeployConcurrency int
+	// SecretMounts maps secret names to the directories where secrets are mounted
+	SecretMounts map[string]string
+	// ServiceAccount of the service pods and build TaskRuns
+	ServiceAccount string
+	// Permissions are granted to the dedicated function ServiceAccount
+	Permissions []rbacv1.PolicyRule
+	// deployed is the knative service object created or updated by the last deployment
+	deployed *servingv1.Service
+	// secrets declared in the manifest
+	secrets []manifestSecret
+	// Decrypt opens manifest secrets values encrypted with "tm secret encrypt"
+	Decrypt func(value string) ([]byte, error)
+	// GitAuth returns credentials to clone private repositories with manifests
+	GitAuth func(url string) (transport.AuthMethod, error)
 	// TODO: get rid of file package dependency
 	Schedule []file.Schedule
 }
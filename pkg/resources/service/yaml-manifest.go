@@ -15,17 +15,24 @@
 package service
 
 import (
-	"context"
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"strings"
+	"sync"
+	"time"
 
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/triggermesh/tm/pkg/client"
 	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/printer"
+	"github.com/triggermesh/tm/pkg/resources/cache"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 )
 
 // Output contains input-output writer interface
@@ -37,11 +44,11 @@ type status struct {
 	Error   error
 }
 
-// DeployYAML accepts service YAML manifest and deploys it to cluster
-func (s *Service) DeployYAML(yamlFile string, functionsToDeploy []string, threads int, clientset *client.ConfigSet) error {
+// DeployYAML accepts service YAML manifest, deploys it to cluster and returns functions results
+func (s *Service) DeployYAML(yamlFile string, functionsToDeploy []string, threads int, clientset *client.ConfigSet) ([]FunctionResult, error) {
 	services, err := s.ManifestToServices(yamlFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var functions []Service
@@ -51,57 +58,146 @@ func (s *Service) DeployYAML(yamlFile string, functionsToDeploy []string, thread
 		}
 	}
 
+	// deploy previously built images instead of building functions again
+	if s.Images != nil {
+		for i := range functions {
+			image, exists := s.Images[functions[i].Name]
+			if !exists {
+				return nil, fmt.Errorf("function %q image is not found in build report", functions[i].Name)
+			}
+			functions[i].Source = image
+		}
+	}
+
+	if err := s.ApplySecrets(clientset); err != nil {
+		return nil, err
+	}
+
 	removeOrphans := (len(functionsToDeploy) == 0)
 
 	return s.DeployFunctions(functions, removeOrphans, threads, clientset)
 }
 
-// DeployFunctions creates a deployment worker pool, reads provided Service array and
-// if service is in list to deploy, sends it to the worker pool with given concurrency rate.
+// DeployFunctions deploys provided functions through the build and deployment worker pools.
 // After deployment it checks which functions from current service are left untouched
 // and removes them as orphans
-func (s *Service) DeployFunctions(functions []Service, removeOrphans bool, threads int, clientset *client.ConfigSet) error {
-	jobs := make(chan Service, 100)
-	results := make(chan status, 100)
-	defer close(jobs)
-	defer close(results)
+func (s *Service) DeployFunctions(functions []Service, removeOrphans bool, threads int, clientset *client.ConfigSet) ([]FunctionResult, error) {
+	var failed int
+	var results []FunctionResult
+	for _, d := range s.runPipeline(functions, threads, clientset) {
+		if d.Error != nil {
+			failed++
+		}
+		results = append(results, d.result())
+	}
 
-	for w := 0; w < threads; w++ {
-		go deploymentWorker(jobs, results, clientset)
+	if removeOrphans && !client.Dry {
+		removed, err := s.removeOrphans(functions, clientset)
+		for _, name := range removed {
+			results = append(results, FunctionResult{Function: name, Status: StatusRemoved})
+		}
+		if err != nil {
+			return results, err
+		}
 	}
 
-	var inProgress int
-	for _, function := range functions {
-		jobs <- function
-		inProgress++
+	if failed != 0 {
+		return results, fmt.Errorf("There were errors during manifest deployment: %d of %d functions failed", failed, len(functions))
 	}
+	return results, nil
+}
 
-	var errs bool
-	for i := 0; i < inProgress; i++ {
-		if r := <-results; r.Error != nil {
-			errs = true
-			fmt.Fprintln(Output, r.Error)
-		} else {
-			fmt.Fprintln(Output, r.Message)
+// runPipeline creates separate build and deployment worker pools and sends functions
+// to the build pool with BuildConcurrency rate. Built functions are passed to the
+// deployment pool which runs with DeployConcurrency rate. Both rates default to threads value.
+// Deployment phases are displayed as a live view followed by the summary table.
+// Processed functions are returned in the same order as they were passed.
+func (s *Service) runPipeline(functions []Service, threads int, clientset *client.ConfigSet) []*deployment {
+	buildThreads, deployThreads := s.BuildConcurrency, s.DeployConcurrency
+	if buildThreads <= 0 {
+		buildThreads = threads
+	}
+	if deployThreads <= 0 {
+		deployThreads = threads
+	}
+
+	// dry run prints objects instead of deployment progress
+	progressOutput := Output
+	if client.Dry {
+		progressOutput = ioutil.Discard
+	}
+	// verbose logs are not postponed and would break the redrawn view
+	interactive := printer.IsTerminal(progressOutput) && !clientset.Log.IsDebug()
+	tracker := printer.NewTracker(progressOutput, interactive)
+
+	// log messages are postponed until the live view is finished
+	var logs bytes.Buffer
+	if tracker.Interactive() {
+		logOutput := clientset.Log.Out
+		clientset.Log.SetOutput(&logs)
+		defer func() {
+			clientset.Log.SetOutput(logOutput)
+			logs.WriteTo(logOutput)
+		}()
+	}
+
+	builds := make(chan *deployment, len(functions))
+	deploys := make(chan *deployment, len(functions))
+	results := make(chan *deployment, len(functions))
+	defer close(deploys)
+
+	// ReadWriteOnce cache volume cannot be mounted into builds
+	// running on different nodes, such builds are serialized
+	volumes := make(map[string]*sync.Mutex)
+	for _, function := range functions {
+		if function.Cache.Exclusive() {
+			volumes[function.Cache.Volume] = &sync.Mutex{}
 		}
 	}
+	for w := 0; w < buildThreads; w++ {
+		go buildWorker(builds, deploys, results, volumes, clientset)
+	}
+	for w := 0; w < deployThreads; w++ {
+		go deploymentWorker(deploys, results, clientset)
+	}
 
-	if removeOrphans && !client.Dry {
-		if err := s.removeOrphans(functions, clientset); err != nil {
-			return err
+	deployments := make([]*deployment, 0, len(functions))
+	for _, function := range functions {
+		d := &deployment{
+			service: function,
+			task:    tracker.Add(function.Name),
+		}
+		d.service.OnPhase = d.task.SetPhase
+		deployments = append(deployments, d)
+		builds <- d
+	}
+	close(builds)
+
+	tracker.Start()
+	for range functions {
+		r := <-results
+		if client.Dry {
+			if r.Error != nil {
+				fmt.Fprintln(Output, r.Error)
+			} else {
+				fmt.Fprintln(Output, r.Message)
+			}
 		}
 	}
+	tracker.Stop()
 
-	if errs {
-		return fmt.Errorf("There were errors during manifest deployment")
+	if !client.Dry && len(functions) != 0 {
+		fmt.Fprintln(Output)
+		printer.NewPrinter(Output).PrintTable(tracker.Summary())
 	}
-	return nil
+	return deployments
 }
 
-// DeleteYAML creates deletion worker pool and removes functions listed in provided YAML manifest
-func (s *Service) DeleteYAML(yamlFile string, functionsToDelete []string, threads int, clientset *client.ConfigSet) error {
+// DeleteYAML creates deletion worker pool, removes functions listed in provided YAML manifest
+// and returns functions results
+func (s *Service) DeleteYAML(yamlFile string, functionsToDelete []string, threads int, clientset *client.ConfigSet) ([]FunctionResult, error) {
 	jobs := make(chan Service, 100)
-	results := make(chan status, 100)
+	results := make(chan FunctionResult, 100)
 	defer close(jobs)
 	defer close(results)
 
@@ -111,7 +207,7 @@ func (s *Service) DeleteYAML(yamlFile string, functionsToDelete []string, thread
 
 	functions, err := s.ManifestToServices(yamlFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var inProgress int
@@ -124,18 +220,25 @@ func (s *Service) DeleteYAML(yamlFile string, functionsToDelete []string, thread
 		inProgress++
 	}
 
+	deleted := make([]FunctionResult, 0, inProgress)
 	for i := 0; i < inProgress; i++ {
-		if r := <-results; r.Error != nil {
+		r := <-results
+		if r.Error != "" {
 			fmt.Fprintln(Output, r.Error)
 		}
+		deleted = append(deleted, r)
 	}
-	return nil
+	// manifest secrets are removed only with the whole service
+	if len(functionsToDelete) == 0 && !client.Dry {
+		return deleted, s.deleteSecrets(clientset)
+	}
+	return deleted, nil
 }
 
 // ManifestToServices parses and validates YAML manifest and returns an array of Service objects
 func (s *Service) ManifestToServices(YAML string) ([]Service, error) {
 	var err error
-	if YAML, err = getYAML(YAML); err != nil {
+	if YAML, err = s.getYAML(YAML); err != nil {
 		return nil, err
 	}
 	definition, err := file.ParseManifest(YAML)
@@ -149,6 +252,8 @@ func (s *Service) ManifestToServices(YAML string) ([]Service, error) {
 	}
 
 	s.setupParentVars(definition)
+	s.secrets = nil
+	s.addSecrets(definition.Secrets, path.Dir(YAML))
 
 	functions := s.parseFunctions(definition.Functions, path.Dir(YAML))
 	includedFunctions, err := s.parseIncludes(definition.Include, path.Dir(YAML))
@@ -165,7 +270,7 @@ func (s *Service) parseIncludes(includes []string, workdir ...string) ([]Service
 		if !file.IsRemote(include) && len(workdir) == 1 {
 			include = path.Join(workdir[0], include)
 		}
-		YAML, err := getYAML(include)
+		YAML, err := s.getYAML(include)
 		if err != nil {
 			return []Service{}, err
 		}
@@ -173,6 +278,7 @@ func (s *Service) parseIncludes(includes []string, workdir ...string) ([]Service
 		if err != nil {
 			return []Service{}, err
 		}
+		s.addSecrets(definition.Secrets, path.Dir(YAML))
 		services = append(services, s.parseFunctions(definition.Functions, path.Dir(YAML))...)
 	}
 
@@ -212,6 +318,9 @@ func (s *Service) setupParentVars(definition file.Definition) {
 	s.PullPolicy = definition.Provider.PullPolicy
 	s.Runtime = definition.Provider.Runtime
 	s.BuildTimeout = definition.Provider.Buildtimeout
+	s.WorkspaceSize = definition.Provider.WorkspaceSize
+	s.ServiceAccount = definition.Provider.ServiceAccount
+	s.Cache = buildCache(definition.Provider.Cache)
 
 	if len(s.Namespace) == 0 {
 		s.Namespace = definition.Provider.Namespace
@@ -239,9 +348,18 @@ func (s *Service) serviceObject(function file.Function) Service {
 		ResultImageTag: "latest",
 		BuildArgs:      function.Buildargs,
 		BuildTimeout:   s.BuildTimeout,
+		BuildRetries:   s.BuildRetries,
+		WorkspaceSize:  s.WorkspaceSize,
+		Cache:          s.Cache,
 		Env:            s.Env,
 		Annotations:    make(map[string]string),
 		EnvSecrets:     append(s.EnvSecrets, function.EnvSecrets...),
+		SecretMounts:   function.SecretMounts,
+		ServiceAccount: s.ServiceAccount,
+		Permissions:    policyRules(function.Permissions),
+	}
+	if function.ServiceAccount != "" {
+		service.ServiceAccount = function.ServiceAccount
 	}
 	// For back-compatibility with old "handler" field
 	if len(function.Handler) != 0 {
@@ -259,20 +377,52 @@ func (s *Service) serviceObject(function file.Function) Service {
 	if len(service.Runtime) == 0 {
 		service.Runtime = s.Runtime
 	}
+	if function.Cache != nil {
+		service.Cache = buildCache(function.Cache)
+	}
 	if len(function.Description) != 0 {
 		service.Annotations["Description"] = fmt.Sprintf("%s\n%s", service.Annotations["Description"], function.Description)
 	}
 	return service
 }
 
-func (s *Service) removeOrphans(created []Service, clientset *client.ConfigSet) error {
-	list, err := clientset.Serving.ServingV1().Services(s.Namespace).List(context.Background(), metav1.ListOptions{
+func policyRules(permissions []file.Permission) []rbacv1.PolicyRule {
+	var rules []rbacv1.PolicyRule
+	for _, p := range permissions {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     p.APIGroups,
+			Resources:     p.Resources,
+			ResourceNames: p.ResourceNames,
+			Verbs:         p.Verbs,
+		})
+	}
+	return rules
+}
+
+func buildCache(c *file.Cache) cache.Cache {
+	if c == nil {
+		return cache.Cache{}
+	}
+	return cache.Cache{
+		Repository: c.Repository,
+		Volume:     c.Volume,
+		Size:       c.Size,
+		AccessMode: c.AccessMode,
+	}
+}
+
+// removeOrphans deletes services deployed from the manifest but not present
+// in created list and returns the names of removed services
+func (s *Service) removeOrphans(created []Service, clientset *client.ConfigSet) ([]string, error) {
+	list, err := clientset.Serving.ServingV1().Services(s.Namespace).List(clientset.Context, metav1.ListOptions{
 		LabelSelector: "service=" + s.Name,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var removed []string
+
 	for _, existing := range list.Items {
 		orphaned := true
 		for _, newService := range created {
@@ -288,20 +438,28 @@ func (s *Service) removeOrphans(created []Service, clientset *client.ConfigSet)
 			}
 			fmt.Fprintf(Output, "Removing orphaned function %s\n", orphan.Name)
 			if err = orphan.Delete(clientset); err != nil {
-				return err
+				return removed, err
 			}
+			removed = append(removed, orphan.Name)
 		}
 	}
-	return nil
+	return removed, nil
 }
 
-func getYAML(filepath string) (string, error) {
+func (s *Service) getYAML(filepath string) (string, error) {
 	if repository, pathToFile := file.IsGitFile(filepath); len(repository) != 0 {
 		filepath = repository
 		yamlFile = pathToFile
 	}
 	if file.IsGit(filepath) {
-		localfilepath, err := file.Clone(filepath)
+		var auth transport.AuthMethod
+		if s.GitAuth != nil {
+			var err error
+			if auth, err = s.GitAuth(filepath); err != nil {
+				return "", fmt.Errorf("git credentials: %s", err)
+			}
+		}
+		localfilepath, err := file.Clone(filepath, auth)
 		if err != nil {
 			return "", err
 		}
@@ -322,20 +480,66 @@ func getYAML(filepath string) (string, error) {
 	return filepath, nil
 }
 
-func deploymentWorker(services <-chan Service, results chan<- status, clientset *client.ConfigSet) {
-	for service := range services {
-		output, err := service.Deploy(clientset)
-		results <- status{
-			Message: output,
-			Error:   err,
+// deployment is a function passing through the build and deployment worker pools
+type deployment struct {
+	status
+	service   Service
+	image     string
+	builder   Builder
+	buildTime time.Duration
+	task      *printer.Task
+}
+
+func (d *deployment) finish(message string, err error) {
+	d.Message, d.Error = message, err
+	d.task.Done(message, err)
+}
+
+func buildWorker(builds <-chan *deployment, deploys, results chan<- *deployment, volumes map[string]*sync.Mutex, clientset *client.ConfigSet) {
+	for d := range builds {
+		lock, exclusive := volumes[d.service.Cache.Volume]
+		if exclusive {
+			lock.Lock()
+		}
+		started := time.Now()
+		image, builder, err := d.service.build(clientset)
+		if exclusive {
+			lock.Unlock()
 		}
+		d.image, d.builder, d.buildTime = image, builder, time.Since(started)
+		if err != nil {
+			d.finish("", err)
+			results <- d
+			continue
+		}
+		if d.service.BuildOnly {
+			d.finish(fmt.Sprintf("Build-only flag set, service image is %s", image), nil)
+			results <- d
+			continue
+		}
+		deploys <- d
 	}
 }
 
-func deletionWorker(services <-chan Service, results chan<- status, clientset *client.ConfigSet) {
+func deploymentWorker(deploys <-chan *deployment, results chan<- *deployment, clientset *client.ConfigSet) {
+	for d := range deploys {
+		d.finish(d.service.deployImage(d.image, d.builder, clientset))
+		results <- d
+	}
+}
+
+func deletionWorker(services <-chan Service, results chan<- FunctionResult, clientset *client.ConfigSet) {
 	for service := range services {
-		results <- status{
-			Error: service.Delete(clientset),
+		started := time.Now()
+		result := FunctionResult{
+			Function: service.Name,
+			Status:   StatusDeleted,
+		}
+		if err := service.Delete(clientset); err != nil {
+			result.Status = StatusFailed
+			result.Error = err.Error()
 		}
+		result.Duration = time.Since(started).Round(time.Second).String()
+		results <- result
 	}
 }
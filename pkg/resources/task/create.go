@@ -15,7 +15,6 @@
 package task
 
 import (
-	"context"
 	"fmt"
 	"io/ioutil"
 	"strings"
@@ -24,6 +23,7 @@ import (
 	tekton "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/triggermesh/tm/pkg/client"
 	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/resources/cache"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,6 +33,22 @@ const (
 	kind              = "Task"
 	api               = "tekton.dev/v1beta1"
 	uploadDoneTrigger = ".uploadIsDone"
+	gitInitImage      = "gcr.io/tekton-releases/github.com/tektoncd/pipeline/cmd/git-init:v0.29.0"
+	sourcesMountPath  = "/workspace/workspace"
+	kanikoImage       = "gcr.io/kaniko-project/executor"
+	cacheVolume       = "tm-build-cache"
+	cacheMountPath    = "/cache"
+)
+
+const (
+	// SourcesWorkspace is the name of the workspace that function sources are cloned into
+	SourcesWorkspace = "sources"
+	// GitURLParam is the name of the task parameter with git repository URL
+	GitURLParam = "GIT_URL"
+	// GitRevisionParam is the name of the task parameter with git revision to checkout
+	GitRevisionParam = "GIT_REVISION"
+	// BuildLabelKey marks build objects created by tm: generated tasks and taskruns
+	BuildLabelKey = "cli.triggermesh.io/build"
 )
 
 // Deploy accepts path (local or URL) to tekton Task manifest and installs it
@@ -65,6 +81,7 @@ func (t *Task) Deploy(clientset *client.ConfigSet) (*tekton.Task, error) {
 	if t.GenerateName != "" {
 		task.SetName("")
 		task.SetGenerateName(t.GenerateName)
+		SetBuildLabel(&task.ObjectMeta)
 	} else if t.Name != "" {
 		task.SetName(t.Name)
 	}
@@ -79,6 +96,10 @@ func (t *Task) Deploy(clientset *client.ConfigSet) (*tekton.Task, error) {
 		clientset.Log.Debugf("setting '--skip-tls-verify' flag for task \"%s/%s\"", task.GetNamespace(), task.GetName())
 		setupArgs(clientset, task)
 	}
+	if t.Cache.Enabled() {
+		clientset.Log.Debugf("setting build cache for task \"%s/%s\"", task.GetNamespace(), task.GetName())
+		setupCache(t.Cache, task)
+	}
 
 	if t.FromLocalSource {
 		clientset.Log.Debugf("adding source uploading step to task \"%s/%s\"", task.GetNamespace(), task.GetGenerateName())
@@ -87,9 +108,16 @@ func (t *Task) Deploy(clientset *client.ConfigSet) (*tekton.Task, error) {
 		task.Spec.Resources = &tekton.TaskResources{}
 		// }
 	}
+	if t.FromGitSource {
+		clientset.Log.Debugf("adding git clone step to task \"%s/%s\"", task.GetNamespace(), task.GetGenerateName())
+		setupGitSource(task)
+	}
 	if client.Dry {
 		return task, nil
 	}
+	if err := clientset.Require(client.TektonPipelines); err != nil {
+		return nil, err
+	}
 	return t.CreateOrUpdate(task, clientset)
 }
 
@@ -100,6 +128,9 @@ func (t *Task) Clone(clientset *client.ConfigSet, task *tekton.Task) (*tekton.Ta
 	task.SetGenerateName(task.GetName() + "-")
 	task.SetName("")
 	task.SetResourceVersion("")
+	task.SetUID("")
+	task.SetOwnerReferences(nil)
+	SetBuildLabel(&task.ObjectMeta)
 	if clientset.Registry.Secret != "" {
 		clientset.Log.Debugf("setting registry secret %q for task \"%s/%s\"", clientset.Registry.Secret, task.GetNamespace(), task.GetName())
 		setupEnv(clientset, task)
@@ -109,6 +140,10 @@ func (t *Task) Clone(clientset *client.ConfigSet, task *tekton.Task) (*tekton.Ta
 		clientset.Log.Debugf("setting '--skip-tls-verify' flag for task \"%s/%s\"", task.GetNamespace(), task.GetName())
 		setupArgs(clientset, task)
 	}
+	if t.Cache.Enabled() {
+		clientset.Log.Debugf("setting build cache for task \"%s/%s\"", task.GetNamespace(), task.GetName())
+		setupCache(t.Cache, task)
+	}
 	if t.FromLocalSource {
 		clientset.Log.Debugf("adding source uploading step to task \"%s/%s\" clone", task.GetNamespace(), task.GetGenerateName())
 		task.Spec.Steps = append([]tekton.Step{t.customStep()}, task.Spec.Steps...)
@@ -116,12 +151,24 @@ func (t *Task) Clone(clientset *client.ConfigSet, task *tekton.Task) (*tekton.Ta
 		task.Spec.Resources = &tekton.TaskResources{}
 		// }
 	}
+	if t.FromGitSource {
+		clientset.Log.Debugf("adding git clone step to task \"%s/%s\" clone", task.GetNamespace(), task.GetGenerateName())
+		setupGitSource(task)
+	}
 	if client.Dry {
 		return task, nil
 	}
 	return t.CreateOrUpdate(task, clientset)
 }
 
+// SetBuildLabel marks object as the build artifact created by tm
+func SetBuildLabel(meta *metav1.ObjectMeta) {
+	if meta.Labels == nil {
+		meta.Labels = make(map[string]string)
+	}
+	meta.Labels[BuildLabelKey] = "true"
+}
+
 func (t *Task) customStep() tekton.Step {
 	return tekton.Step{
 		Name:    "sources-receiver",
@@ -143,6 +190,63 @@ func (t *Task) customStep() tekton.Step {
 	}
 }
 
+// GitCloneStep returns tekton Step that clones git repository passed in task
+// parameters into the sources workspace
+func GitCloneStep() tekton.Step {
+	return tekton.Step{
+		Name:    "git-clone",
+		Image:   gitInitImage,
+		Command: []string{"/ko-app/git-init"},
+		Args: []string{
+			"-url", fmt.Sprintf("$(params.%s)", GitURLParam),
+			"-revision", fmt.Sprintf("$(params.%s)", GitRevisionParam),
+			"-path", fmt.Sprintf("$(workspaces.%s.path)", SourcesWorkspace),
+		},
+	}
+}
+
+// GitSourceParams returns parameters and workspace declarations required by GitCloneStep
+func GitSourceParams() ([]tekton.ParamSpec, []tekton.WorkspaceDeclaration) {
+	params := []tekton.ParamSpec{
+		{
+			Name:        GitURLParam,
+			Type:        tekton.ParamTypeString,
+			Description: "Git repository URL",
+		}, {
+			Name:        GitRevisionParam,
+			Type:        tekton.ParamTypeString,
+			Description: "Git revision (branch, tag, commit SHA or ref)",
+			Default: &tekton.ArrayOrString{
+				Type:      tekton.ParamTypeString,
+				StringVal: "master",
+			},
+		},
+	}
+	workspaces := []tekton.WorkspaceDeclaration{
+		{
+			Name:        SourcesWorkspace,
+			Description: "Function sources",
+			MountPath:   sourcesMountPath,
+		},
+	}
+	return params, workspaces
+}
+
+// setupGitSource replaces git PipelineResource input with the clone step
+// that puts sources into the same path where runtimes expect them
+func setupGitSource(task *tekton.Task) {
+	for _, ws := range task.Spec.Workspaces {
+		if ws.Name == SourcesWorkspace {
+			return
+		}
+	}
+	params, workspaces := GitSourceParams()
+	task.Spec.Steps = append([]tekton.Step{GitCloneStep()}, task.Spec.Steps...)
+	task.Spec.Params = append(task.Spec.Params, params...)
+	task.Spec.Workspaces = append(task.Spec.Workspaces, workspaces...)
+	task.Spec.Resources = &tekton.TaskResources{}
+}
+
 func (t *Task) readYAML() (*tekton.Task, error) {
 	var res tekton.Task
 	yamlFile, err := ioutil.ReadFile(t.File)
@@ -154,7 +258,7 @@ func (t *Task) readYAML() (*tekton.Task, error) {
 
 // CreateOrUpdate creates new tekton Task object or updates existing one
 func (t *Task) CreateOrUpdate(task *tekton.Task, clientset *client.ConfigSet) (*tekton.Task, error) {
-	ctx := context.Background()
+	ctx := clientset.Context
 	if task.GetGenerateName() != "" {
 		return clientset.TektonTasks.TektonV1beta1().Tasks(t.Namespace).Create(ctx, task, metav1.CreateOptions{})
 	}
@@ -173,7 +277,7 @@ func (t *Task) CreateOrUpdate(task *tekton.Task, clientset *client.ConfigSet) (*
 
 // SetOwner updates tekton Task object with provided owner reference
 func (t *Task) SetOwner(clientset *client.ConfigSet, owner metav1.OwnerReference) error {
-	ctx := context.Background()
+	ctx := clientset.Context
 	task, err := clientset.TektonTasks.TektonV1beta1().Tasks(t.Namespace).Get(ctx, t.Name, metav1.GetOptions{})
 	if err != nil {
 		return err
@@ -227,20 +331,74 @@ func setupVolume(clientset *client.ConfigSet, task *tekton.Task) {
 func setupArgs(clientset *client.ConfigSet, task *tekton.Task) {
 	// not the best way to add kaniko build arguments
 	for i, step := range task.Spec.Steps {
-		exportStep := false
-		if !strings.HasPrefix(step.Image, "gcr.io/kaniko-project/executor") {
+		if !isKanikoExportStep(step) {
 			continue
 		}
-		for _, arg := range step.Args {
-			if strings.HasPrefix(arg, "--destination=") {
-				exportStep = true
-				break
-			}
-		}
-		// TODO check if "--skip-tls-verify" is already set
-		if exportStep {
+		if !hasArg(step.Args, "--skip-tls-verify") {
 			task.Spec.Steps[i].Args = append(step.Args, "--skip-tls-verify")
-			break
+		}
+		break
+	}
+}
+
+func isKanikoExportStep(step tekton.Step) bool {
+	if !strings.HasPrefix(step.Image, kanikoImage) {
+		return false
+	}
+	for _, arg := range step.Args {
+		if strings.HasPrefix(arg, "--destination=") {
+			return true
+		}
+	}
+	return false
+}
+
+// setupCache configures kaniko build steps to use layers cache in registry repository
+// and mounts cache volume into every task step so that other builders could reuse it too
+func setupCache(c cache.Cache, task *tekton.Task) {
+	if c.Volume != "" {
+		task.Spec.Volumes = append(task.Spec.Volumes, corev1.Volume{
+			Name: cacheVolume,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: c.Volume,
+				},
+			},
+		})
+	}
+	for i, step := range task.Spec.Steps {
+		if c.Volume != "" {
+			task.Spec.Steps[i].VolumeMounts = append(step.VolumeMounts, corev1.VolumeMount{
+				Name:      cacheVolume,
+				MountPath: cacheMountPath,
+			})
+			task.Spec.Steps[i].Env = append(step.Env, corev1.EnvVar{
+				Name:  "TM_CACHE_DIR",
+				Value: cacheMountPath,
+			})
+		}
+		if !isKanikoExportStep(step) {
+			continue
+		}
+		args := task.Spec.Steps[i].Args
+		if !hasArg(args, "--cache") {
+			args = append(args, "--cache=true")
+		}
+		if c.Repository != "" && !hasArg(args, "--cache-repo") {
+			args = append(args, "--cache-repo="+c.Repository)
+		}
+		if c.Volume != "" && !hasArg(args, "--cache-dir") {
+			args = append(args, "--cache-dir="+cacheMountPath)
+		}
+		task.Spec.Steps[i].Args = args
+	}
+}
+
+func hasArg(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == name || strings.HasPrefix(arg, name+"=") {
+			return true
 		}
 	}
+	return false
 }
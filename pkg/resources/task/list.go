@@ -15,7 +15,6 @@
 package task
 
 import (
-	"context"
 	"time"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -23,6 +22,7 @@ import (
 	"github.com/triggermesh/tm/pkg/printer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 // GetTable converts k8s list instance into printable object
@@ -58,5 +58,20 @@ func (t *Task) row(item *v1beta1.Task) []string {
 
 // List returns k8s list object
 func (t *Task) List(clientset *client.ConfigSet) (*v1beta1.TaskList, error) {
-	return clientset.TektonTasks.TektonV1beta1().Tasks(t.Namespace).List(context.Background(), metav1.ListOptions{})
+	list := &v1beta1.TaskList{}
+	err := client.ListPages(client.ListOptions(), func(opts metav1.ListOptions) (string, error) {
+		page, err := clientset.TektonTasks.TektonV1beta1().Tasks(t.Namespace).List(clientset.Context, opts)
+		if err != nil {
+			return "", err
+		}
+		list.Items = append(list.Items, page.Items...)
+		list.ResourceVersion = page.ResourceVersion
+		return page.Continue, nil
+	})
+	return list, err
+}
+
+// Watch opens watch of tasks changes
+func (t *Task) Watch(clientset *client.ConfigSet, opts metav1.ListOptions) (watch.Interface, error) {
+	return clientset.TektonTasks.TektonV1beta1().Tasks(t.Namespace).Watch(clientset.Context, opts)
 }
@@ -19,7 +19,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	tekton "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	"github.com/triggermesh/tm/pkg/resources/cache"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestList(t *testing.T) {
@@ -27,13 +31,13 @@ func TestList(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewClient(&tekton.Task{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: namespace}})
 
 	task := &Task{Name: "Foo", Namespace: namespace}
 
-	_, err = task.List(&testClient)
+	list, err := task.List(&testClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
@@ -41,13 +45,11 @@ func TestGet(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewClient()
 
 	task := &Task{Name: "Foo", Namespace: namespace}
-	result, err := task.Get(&testClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	_, err := task.Get(&testClient)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
 
 func TestDelete(t *testing.T) {
@@ -55,10 +57,62 @@ func TestDelete(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewClient()
 
 	task := &Task{Name: "Foo", Namespace: namespace}
-	err = task.Delete(&testClient)
+	err := task.Delete(&testClient)
 	assert.Error(t, err)
 }
+
+func TestSetupGitSource(t *testing.T) {
+	task := &tekton.Task{
+		Spec: tekton.TaskSpec{
+			Resources: &tekton.TaskResources{
+				Inputs: []tekton.TaskResource{
+					{ResourceDeclaration: tekton.ResourceDeclaration{Name: "sources", Type: tekton.PipelineResourceTypeGit}},
+				},
+			},
+			Steps: []tekton.Step{{Name: "build"}},
+		},
+	}
+
+	setupGitSource(task)
+	assert.Len(t, task.Spec.Steps, 2)
+	assert.Equal(t, "git-clone", task.Spec.Steps[0].Name)
+	assert.Empty(t, task.Spec.Resources.Inputs)
+	assert.Len(t, task.Spec.Params, 2)
+	assert.Len(t, task.Spec.Workspaces, 1)
+
+	// second call must not duplicate the clone step
+	setupGitSource(task)
+	assert.Len(t, task.Spec.Steps, 2)
+}
+
+func TestSetupCache(t *testing.T) {
+	newTask := func() *tekton.Task {
+		return &tekton.Task{
+			Spec: tekton.TaskSpec{
+				Steps: []tekton.Step{
+					{Name: "prepare", Image: "busybox"},
+					{Name: "export", Image: "gcr.io/kaniko-project/executor:latest", Args: []string{"--destination=foo", "--cache=false"}},
+				},
+			},
+		}
+	}
+
+	task := newTask()
+	setupCache(cache.Cache{Repository: "registry/cache"}, task)
+	assert.Equal(t, []string{"--destination=foo", "--cache=false", "--cache-repo=registry/cache"}, task.Spec.Steps[1].Args)
+	assert.Empty(t, task.Spec.Volumes)
+	assert.Empty(t, task.Spec.Steps[0].Args)
+
+	task = newTask()
+	task.Spec.Steps[1].Args = []string{"--destination=foo"}
+	setupCache(cache.Cache{Volume: "build-cache"}, task)
+	assert.Equal(t, []string{"--destination=foo", "--cache=true", "--cache-dir=/cache"}, task.Spec.Steps[1].Args)
+	assert.Len(t, task.Spec.Volumes, 1)
+	assert.Equal(t, "build-cache", task.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	for _, step := range task.Spec.Steps {
+		assert.Len(t, step.VolumeMounts, 1)
+	}
+}
@@ -14,6 +14,8 @@
 
 package task
 
+import "github.com/triggermesh/tm/pkg/resources/cache"
+
 // Task represents tekton Task object
 type Task struct {
 	File            string
@@ -21,4 +23,6 @@ type Task struct {
 	Name            string
 	Namespace       string
 	FromLocalSource bool
+	FromGitSource   bool
+	Cache           cache.Cache
 }
@@ -21,20 +21,28 @@ import (
 	"fmt"
 	"os"
 	"path"
-	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/ghodss/yaml"
-	v1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/triggermesh/tm/pkg/client"
 	"github.com/triggermesh/tm/pkg/file"
+	"github.com/triggermesh/tm/pkg/printer"
 	"github.com/triggermesh/tm/pkg/resources/clustertask"
+	"github.com/triggermesh/tm/pkg/resources/credential"
 	"github.com/triggermesh/tm/pkg/resources/pipelineresource"
 	"github.com/triggermesh/tm/pkg/resources/task"
+	"golang.org/x/crypto/ssh/terminal"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"knative.dev/pkg/apis"
 )
 
@@ -44,10 +52,18 @@ const (
 	taskKind          = "Task"
 	clusterTaskKind   = "ClusterTask"
 	uploadDoneTrigger = ".uploadIsDone"
+	// time to wait for registry response when image digest is requested
+	digestTimeout = 10 * time.Second
+	// time to wait for TaskRun cancellation request after the build failure or interrupt
+	cancelTimeout = 10 * time.Second
 )
 
+// buildRetryDelay is the initial delay before failed build is retried, doubled on every attempt
+var buildRetryDelay = 5 * time.Second
+
 // Deploy prepares and verifies tekton resources (Task and PipelineResource) required for TaskRun,
 // creates TaskRun object and optionally waits for its result.
+// Failed TaskRun is re-created up to Retries times.
 // Deploy function returns resulting image URL and build error.
 func (tr *TaskRun) Deploy(clientset *client.ConfigSet) (string, error) {
 	if tr.Name == "" {
@@ -56,12 +72,19 @@ func (tr *TaskRun) Deploy(clientset *client.ConfigSet) (string, error) {
 	if tr.Task.Name == "" {
 		return "", fmt.Errorf("task name cannot be empty")
 	}
+	// git sources are cloned into the TaskRun workspace unless
+	// PipelineResource is explicitly set
+	fromGit := tr.PipelineResource.Name == "" && file.IsGit(tr.Function.Path)
 	if !client.Dry {
-		if err := tr.prepareTask(clientset); err != nil {
-			return "", fmt.Errorf("setup task: %s", err)
+		// oversized sources must fail before any build object is created
+		if err := tr.checkSources(clientset); err != nil {
+			return "", err
 		}
-		if err := tr.preparePipelineresources(clientset); err != nil {
-			return "", fmt.Errorf("setup pipelineresource: %s", err)
+		if err := clientset.Require(client.TektonPipelines); err != nil {
+			return "", err
+		}
+		if err := tr.prepareTask(clientset, fromGit); err != nil {
+			return "", fmt.Errorf("setup task: %s", err)
 		}
 	}
 	if err := tr.checkPipelineResource(clientset); err != nil {
@@ -73,19 +96,44 @@ func (tr *TaskRun) Deploy(clientset *client.ConfigSet) (string, error) {
 	}
 	image = fmt.Sprintf("%s:%s", image, file.RandString(6))
 	clientset.Log.Debugf("taskrun \"%s/%s\" output image will be %q", tr.Namespace, tr.Name, image)
-	taskRunObject := tr.newTaskRun()
-	taskRunObject.Spec.Params = tr.getBuildArguments(image)
-
+	implicitParams := map[string]string{
+		imageParam: image,
+	}
+	if fromGit {
+		implicitParams[task.GitURLParam] = tr.Function.Path
+		if tr.Function.Revision != "" {
+			implicitParams[task.GitRevisionParam] = tr.Function.Revision
+		}
+	}
 	if file.IsLocal(tr.Function.Path) {
 		if file.IsDir(tr.Function.Path) {
 			tr.Function.Path = path.Clean(tr.Function.Path)
 		} else {
-			tr.Params = append(tr.Params, "HANDLER="+path.Base(tr.Function.Path))
+			implicitParams[handlerParam] = path.Base(tr.Function.Path)
 			tr.Function.Path = path.Clean(path.Dir(tr.Function.Path))
 		}
 		clientset.Log.Debugf("function path is %q", tr.Function.Path)
 	}
 
+	// task is not created in dry run, build arguments are not validated
+	var paramSpecs []v1beta1.ParamSpec
+	if !client.Dry {
+		if paramSpecs, err = tr.taskParams(clientset); err != nil {
+			return "", fmt.Errorf("reading task params: %s", err)
+		}
+	}
+	taskRunObject := tr.newTaskRun()
+	if taskRunObject.Spec.Params, err = tr.buildParams(paramSpecs, !client.Dry, implicitParams); err != nil {
+		return "", err
+	}
+	if fromGit {
+		workspace, err := tr.sourcesWorkspace()
+		if err != nil {
+			return "", fmt.Errorf("sources workspace: %s", err)
+		}
+		taskRunObject.Spec.Workspaces = []v1beta1.WorkspaceBinding{workspace}
+	}
+
 	if client.Dry {
 		var taskObj []byte
 		if client.Output == "yaml" {
@@ -96,57 +144,145 @@ func (tr *TaskRun) Deploy(clientset *client.ConfigSet) (string, error) {
 		return string(taskObj), err
 	}
 
-	taskRunObject, err = clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).Create(context.Background(), taskRunObject, metav1.CreateOptions{})
+	for attempt := 0; ; attempt++ {
+		created, err := tr.run(clientset, taskRunObject.DeepCopy(), image, attempt == 0)
+		if err == nil {
+			return image, nil
+		}
+		if !created || attempt >= tr.Retries || clientset.Context.Err() != nil {
+			return "", err
+		}
+		delay := buildRetryDelay << attempt
+		clientset.Log.Warnf("Build %q failed: %s. Retrying in %s", tr.Name, err, delay)
+		select {
+		case <-time.After(delay):
+		case <-clientset.Context.Done():
+			return "", clientset.Context.Err()
+		}
+	}
+}
+
+// run creates TaskRun object, uploads local sources and waits for the build result.
+// Returned boolean value is true if TaskRun has been created, so that the build may be retried.
+// If the build fails or the command is interrupted, created TaskRun is cancelled,
+// so that it does not keep waiting for the sources.
+func (tr *TaskRun) run(clientset *client.ConfigSet, taskRunObject *v1beta1.TaskRun, image string, setTaskOwner bool) (_ bool, err error) {
+	if err := clientset.Context.Err(); err != nil {
+		return false, err
+	}
+	taskRunObject, err = clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).Create(clientset.Context, taskRunObject, metav1.CreateOptions{})
 	if err != nil {
-		return "", fmt.Errorf("creating taskrun: %s", err)
+		return false, fmt.Errorf("creating taskrun: %s", err)
 	}
 	tr.Name = taskRunObject.GetName()
 	clientset.Log.Debugf("taskrun \"%s/%s\" created", tr.Namespace, tr.Name)
 
+	defer func() {
+		if err == nil && clientset.Context.Err() == nil {
+			return
+		}
+		if err := tr.cancel(clientset); err != nil {
+			clientset.Log.Errorf("Can't cancel taskrun %q: %s", tr.Name, err)
+			return
+		}
+		clientset.Log.Warnf("Taskrun %q cancelled", tr.Name)
+	}()
+
 	task := task.Task{
 		Name:      tr.Task.Name,
 		Namespace: tr.Namespace,
 	}
 
 	ownerRef := owner(taskRunObject)
-	if tr.Task.Owned {
+	if tr.Task.Owned && setTaskOwner {
 		err = task.SetOwner(clientset, ownerRef)
 		if err != nil {
 			if err := task.Delete(clientset); err != nil {
 				clientset.Log.Errorf("Can't cleanup task: %s", err)
 			}
-			return "", err
+			return false, err
 		}
 	}
-	if tr.PipelineResource.Owned {
-		clientset.Log.Debugf("setting pipelineresource owner")
-		tr.setPipelineResourceOwner(clientset, ownerRef)
-	}
 	if file.IsLocal(tr.Function.Path) {
+		tr.phase(printer.PhaseUpload)
 		pod, err := tr.taskPod(clientset)
 		if err != nil {
-			return "", fmt.Errorf("getting taskrun pod: %s", err)
+			return true, fmt.Errorf("getting taskrun pod: %s", err)
 		}
 		sourceContainer, err := tr.sourceContainer(clientset, pod)
 		if err != nil {
-			return "", fmt.Errorf("waiting for source container: %s", err)
+			return true, fmt.Errorf("waiting for source container: %s", err)
 		}
 		clientset.Log.Infof("Uploading %q to %s", tr.Function.Path, pod)
 		if err := tr.injectSources(clientset, pod, sourceContainer); err != nil {
-			return "", fmt.Errorf("injecting sources: %s", err)
+			return true, fmt.Errorf("injecting sources: %s", err)
 		}
 	}
+	tr.phase(printer.PhaseBuild)
 	if tr.Wait {
 		clientset.Log.Infof("Waiting for taskrun %q ready state", taskRunObject.Name)
-		if err = tr.wait(clientset); err != nil {
-			return image, fmt.Errorf("taskrun %q deployment failed: %s", tr.Name, err)
+		result, err := tr.wait(clientset)
+		if err != nil {
+			return true, fmt.Errorf("taskrun %q deployment failed: %s", tr.Name, err)
 		}
+		tr.Digest = tr.imageDigest(clientset, result, image)
 	}
-	return image, err
+	return true, nil
 }
 
-func (tr *TaskRun) prepareTask(clientset *client.ConfigSet) error {
-	newTask, err := tr.setupTask(clientset)
+// imageDigest returns the digest of the built image. It is read from the TaskRun
+// results if the task provides one, otherwise the registry is requested.
+// Empty string is returned if the digest cannot be found.
+func (tr *TaskRun) imageDigest(clientset *client.ConfigSet, taskrun *v1beta1.TaskRun, image string) string {
+	for _, result := range taskrun.Status.TaskRunResults {
+		switch result.Name {
+		case "IMAGE_DIGEST", "IMAGE-DIGEST", "digest":
+			return strings.TrimSpace(result.Value.StringVal)
+		}
+	}
+	for _, result := range taskrun.Status.ResourcesResult {
+		if result.Key == "digest" {
+			return strings.TrimSpace(result.Value)
+		}
+	}
+
+	var opts []name.Option
+	if clientset.Registry.SkipTLS {
+		opts = append(opts, name.Insecure)
+	}
+	ref, err := name.ParseReference(image, opts...)
+	if err != nil {
+		clientset.Log.Debugf("parsing image reference %q: %s", image, err)
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(clientset.Context, digestTimeout)
+	defer cancel()
+	desc, err := remote.Head(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithContext(ctx))
+	if err != nil {
+		clientset.Log.Debugf("requesting image %q digest: %s", image, err)
+		return ""
+	}
+	return desc.Digest.String()
+}
+
+// cancel sets TaskRun spec status to cancelled, tekton stops the build pod then.
+// Command context may be already cancelled at this point, so the request has its own timeout
+func (tr *TaskRun) cancel(clientset *client.ConfigSet) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+	patch := fmt.Sprintf(`[{"op":"add","path":"/spec/status","value":%q}]`, v1beta1.TaskRunSpecStatusCancelled)
+	_, err := clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).Patch(ctx, tr.Name, types.JSONPatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+func (tr *TaskRun) prepareTask(clientset *client.ConfigSet, fromGit bool) error {
+	if tr.Cache.Volume != "" {
+		tr.Cache.Namespace = tr.Namespace
+		if err := tr.Cache.CreateVolume(clientset); err != nil {
+			return fmt.Errorf("cache volume: %s", err)
+		}
+	}
+	newTask, err := tr.setupTask(clientset, fromGit)
 	if err != nil {
 		return fmt.Errorf("task %q setup: %s", tr.Task.Name, err)
 	}
@@ -157,35 +293,40 @@ func (tr *TaskRun) prepareTask(clientset *client.ConfigSet) error {
 	return nil
 }
 
-func (tr *TaskRun) preparePipelineresources(clientset *client.ConfigSet) error {
-	if tr.PipelineResource.Name == "" && file.IsGit(tr.Function.Path) {
-		newPplRes, err := tr.setupPipelineresources(clientset)
-		if err != nil {
-			return fmt.Errorf("pipelineresource setup: %s", err)
-		}
-		tr.PipelineResource.Name = newPplRes.Name
-		tr.PipelineResource.Owned = true
+// sourcesWorkspace returns the binding for the workspace where git sources are cloned into.
+// EmptyDir volume is used by default, if workspace size is set, the volume claim is requested instead.
+func (tr *TaskRun) sourcesWorkspace() (v1beta1.WorkspaceBinding, error) {
+	binding := v1beta1.WorkspaceBinding{
+		Name: task.SourcesWorkspace,
 	}
-	return nil
-}
-
-func (tr *TaskRun) setupPipelineresources(clientset *client.ConfigSet) (*v1alpha1.PipelineResource, error) {
-	plr := pipelineresource.PipelineResource{
-		Name:      tr.Name,
-		Namespace: tr.Namespace,
-		Source: pipelineresource.Git{
-			URL:      tr.Function.Path,
-			Revision: tr.Function.Revision,
+	if tr.WorkspaceSize == "" {
+		binding.EmptyDir = &corev1.EmptyDirVolumeSource{}
+		return binding, nil
+	}
+	size, err := resource.ParseQuantity(tr.WorkspaceSize)
+	if err != nil {
+		return binding, fmt.Errorf("workspace size %q: %s", tr.WorkspaceSize, err)
+	}
+	binding.VolumeClaimTemplate = &corev1.PersistentVolumeClaim{
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
 		},
 	}
-	return plr.Deploy(clientset)
+	return binding, nil
 }
 
-func (tr *TaskRun) setupTask(clientset *client.ConfigSet) (*v1beta1.Task, error) {
+func (tr *TaskRun) setupTask(clientset *client.ConfigSet, fromGit bool) (*v1beta1.Task, error) {
 	task := task.Task{
 		Name:            tr.Task.Name,
 		Namespace:       tr.Namespace,
 		FromLocalSource: file.IsLocal(tr.Function.Path),
+		FromGitSource:   fromGit,
+		Cache:           tr.Cache,
 	}
 	taskObj, err := task.Get(clientset)
 	if err != nil {
@@ -203,7 +344,7 @@ func (tr *TaskRun) setupTask(clientset *client.ConfigSet) (*v1beta1.Task, error)
 		taskObj.ObjectMeta = clustertaskObj.ObjectMeta
 		tr.Task.ClusterScope = true
 	}
-	if clientset.Registry.Secret != "" || task.FromLocalSource {
+	if clientset.Registry.Secret != "" || task.FromLocalSource || task.FromGitSource || task.Cache.Enabled() {
 		tr.Task.ClusterScope = false
 		clientset.Log.Debugf("cloning task to a new object \"%s/%s\"", task.Namespace, task.Name)
 		return task.Clone(clientset, taskObj)
@@ -211,18 +352,6 @@ func (tr *TaskRun) setupTask(clientset *client.ConfigSet) (*v1beta1.Task, error)
 	return nil, nil
 }
 
-func (tr *TaskRun) setPipelineResourceOwner(clientset *client.ConfigSet, ownerRef metav1.OwnerReference) {
-	plr := pipelineresource.PipelineResource{
-		Name:      tr.PipelineResource.Name,
-		Namespace: tr.Namespace,
-	}
-	if err := plr.SetOwner(clientset, ownerRef); err != nil {
-		if err = plr.Delete(clientset); err != nil {
-			clientset.Log.Errorf("Can't remove pipelineresource: %s", err)
-		}
-	}
-}
-
 func owner(taskRunObject *v1beta1.TaskRun) metav1.OwnerReference {
 	return metav1.OwnerReference{
 		APIVersion: "tekton.dev/v1beta1",
@@ -268,8 +397,9 @@ func (tr *TaskRun) newTaskRun() *v1beta1.TaskRun {
 			Namespace:    tr.Namespace,
 		},
 		Spec: v1beta1.TaskRunSpec{
-			TaskRef:   taskref,
-			Resources: &v1beta1.TaskRunResources{},
+			TaskRef:            taskref,
+			ServiceAccountName: tr.ServiceAccount,
+			Resources:          &v1beta1.TaskRunResources{},
 			// Inputs:  &v1alpha1.TaskRunInputs{},
 			// PodTemplate: v1alpha1.PodTemplate{
 			// SecurityContext: &corev1.PodSecurityContext{
@@ -278,6 +408,7 @@ func (tr *TaskRun) newTaskRun() *v1beta1.TaskRun {
 			// },
 		},
 	}
+	task.SetBuildLabel(&taskrun.ObjectMeta)
 	if tr.PipelineResource.Name != "" {
 		taskrun.Spec.Resources.Inputs = []v1beta1.TaskResourceBinding{
 			{
@@ -298,7 +429,7 @@ func (tr *TaskRun) imageName(clientset *client.ConfigSet) (string, error) {
 	if len(clientset.Registry.Secret) == 0 {
 		return fmt.Sprintf("%s/%s/%s", clientset.Registry.Host, tr.Namespace, tr.Name), nil
 	}
-	secret, err := clientset.Core.CoreV1().Secrets(tr.Namespace).Get(context.Background(), clientset.Registry.Secret, metav1.GetOptions{})
+	secret, err := clientset.Core.CoreV1().Secrets(tr.Namespace).Get(clientset.Context, clientset.Registry.Secret, metav1.GetOptions{})
 	if err != nil {
 		return "", err
 	}
@@ -308,19 +439,39 @@ func (tr *TaskRun) imageName(clientset *client.ConfigSet) (string, error) {
 	if err := dec.Decode(&config); err != nil {
 		return "", err
 	}
-	if len(config.Auths) > 1 {
-		return "", errors.New("credentials with multiple registries not supported")
-	}
 	if url, ok := gitlabEnv(); ok {
 		return fmt.Sprintf("%s/%s", url, tr.Name), nil
 	}
-	for host, creds := range config.Auths {
-		if config.Project != "" {
-			return fmt.Sprintf("%s/%s/%s", host, config.Project, tr.Name), nil
+	host, creds, err := config.Auths.match(clientset.Registry.Host)
+	if err != nil {
+		return "", fmt.Errorf("secret %q: %s", clientset.Registry.Secret, err)
+	}
+	if config.Project != "" {
+		return fmt.Sprintf("%s/%s/%s", host, config.Project, tr.Name), nil
+	}
+	return fmt.Sprintf("%s/%s/%s", host, creds.username(), tr.Name), nil
+}
+
+// match returns the registry host and credentials. If there are several registries,
+// the one matching the registry host argument is selected.
+func (r registry) match(host string) (string, credentials, error) {
+	switch len(r) {
+	case 0:
+		return "", credentials{}, errors.New("empty registry credentials")
+	case 1:
+		for h, creds := range r {
+			return credential.RegistryHost(h), creds, nil
+		}
+	}
+	hosts := make([]string, 0, len(r))
+	for h, creds := range r {
+		if credential.RegistryHost(h) == credential.RegistryHost(host) {
+			return credential.RegistryHost(h), creds, nil
 		}
-		return fmt.Sprintf("%s/%s/%s", host, creds.Username, tr.Name), nil
+		hosts = append(hosts, credential.RegistryHost(h))
 	}
-	return "", errors.New("empty registry credentials")
+	sort.Strings(hosts)
+	return "", credentials{}, fmt.Errorf("registry %q not found, use --registry-host to select one of %s", host, strings.Join(hosts, ", "))
 }
 
 // hack to use correct username in image URL instead of "gitlab-ci-token" in Gitlab CI
@@ -328,23 +479,16 @@ func gitlabEnv() (string, bool) {
 	return os.LookupEnv("CI_REGISTRY_IMAGE")
 }
 
-func (tr *TaskRun) wait(clientset *client.ConfigSet) error {
-	trWatchInterface, err := clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).Watch(context.Background(), metav1.ListOptions{
+func (tr *TaskRun) wait(clientset *client.ConfigSet) (*v1beta1.TaskRun, error) {
+	var result *v1beta1.TaskRun
+	err := client.Watch(clientset.Context, metav1.ListOptions{
 		FieldSelector: fmt.Sprintf("metadata.name=%s", tr.Name),
-	})
-	if err != nil || trWatchInterface == nil {
-		return fmt.Errorf("can't get watch interface: %s", err)
-	}
-	defer trWatchInterface.Stop()
-
-	for {
-		event := <-trWatchInterface.ResultChan()
-		if event.Object == nil {
-			return tr.wait(clientset)
-		}
+	}, func(opts metav1.ListOptions) (watch.Interface, error) {
+		return clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).Watch(clientset.Context, opts)
+	}, func(event watch.Event) (bool, error) {
 		taskrun, ok := event.Object.(*v1beta1.TaskRun)
 		if !ok || taskrun == nil {
-			continue
+			return false, nil
 		}
 		if clientset.Log.IsDebug() {
 			clientset.Log.Debugf("got new event:")
@@ -354,107 +498,121 @@ func (tr *TaskRun) wait(clientset *client.ConfigSet) error {
 		}
 		for _, v := range taskrun.Status.Conditions {
 			if v.IsFalse() && v.Severity == apis.ConditionSeverityError {
-				return errors.New(v.Message)
+				return false, errors.New(v.Message)
 			}
 		}
-		if taskrun.IsDone() {
-			return nil
-		}
+		result = taskrun
+		return taskrun.IsDone(), nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
 }
 
 // SetOwner updates TaskRun object with provided owner reference
 func (tr *TaskRun) SetOwner(clientset *client.ConfigSet, owner metav1.OwnerReference) error {
-	taskrun, err := clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).Get(context.Background(), tr.Name, metav1.GetOptions{})
+	taskrun, err := clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).Get(clientset.Context, tr.Name, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
 	clientset.Log.Debugf("setting taskrun \"%s/%s\" owner to %s/%s", taskrun.GetNamespace(), taskrun.GetName(), owner.Kind, owner.Name)
 	taskrun.SetOwnerReferences([]metav1.OwnerReference{owner})
-	_, err = clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).Update(context.Background(), taskrun, metav1.UpdateOptions{})
+	_, err = clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).Update(clientset.Context, taskrun, metav1.UpdateOptions{})
 	return err
 }
 
-func (tr *TaskRun) taskPod(clientset *client.ConfigSet) (string, error) {
-	watch, err := clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).Watch(context.Background(), metav1.ListOptions{
-		FieldSelector: "metadata.name=" + tr.Name,
-	})
-	if err != nil || watch == nil {
-		return "", fmt.Errorf("Can't get taskrun %q watch interface", tr.Name)
-	}
-	defer watch.Stop()
-
+// timeout returns context cancelled after the taskrun timeout, 10 minutes by default
+func (tr *TaskRun) timeout(clientset *client.ConfigSet) (context.Context, context.CancelFunc) {
 	duration, err := time.ParseDuration(tr.Timeout)
 	if err != nil {
 		duration = 10 * time.Minute
 	}
+	return context.WithTimeout(clientset.Context, duration)
+}
 
-	ticker := time.NewTicker(duration)
-	defer ticker.Stop()
+func (tr *TaskRun) taskPod(clientset *client.ConfigSet) (string, error) {
+	ctx, cancel := tr.timeout(clientset)
+	defer cancel()
 
-	for {
-		select {
-		case event := <-watch.ResultChan():
-			if event.Object == nil {
-				return tr.taskPod(clientset)
-			}
-			res, ok := event.Object.(*v1beta1.TaskRun)
-			if !ok || res == nil {
-				continue
-			}
-			status := res.Status.GetCondition(apis.ConditionType("Succeeded"))
-			if status != nil && status.IsFalse() {
-				return "", fmt.Errorf("taskrun failed: %s", status.Message)
-			}
-			if pod := res.Status.PodName; pod != "" {
-				return pod, nil
-			}
-		case <-ticker.C:
-			return "", fmt.Errorf("watch taskrun timeout")
+	var pod string
+	err := client.Watch(ctx, metav1.ListOptions{
+		FieldSelector: "metadata.name=" + tr.Name,
+	}, func(opts metav1.ListOptions) (watch.Interface, error) {
+		return clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).Watch(ctx, opts)
+	}, func(event watch.Event) (bool, error) {
+		res, ok := event.Object.(*v1beta1.TaskRun)
+		if !ok || res == nil {
+			return false, nil
 		}
-	}
+		status := res.Status.GetCondition(apis.ConditionSucceeded)
+		if status != nil && status.IsFalse() {
+			return false, fmt.Errorf("taskrun failed: %s", status.Message)
+		}
+		pod = res.Status.PodName
+		return pod != "", nil
+	})
+	switch {
+	case err == nil:
+		return pod, nil
+	case clientset.Context.Err() != nil:
+		return "", clientset.Context.Err()
+	case ctx.Err() != nil:
+		return "", fmt.Errorf("watch taskrun timeout")
+	}
+	return "", err
 }
 
 func (tr *TaskRun) sourceContainer(clientset *client.ConfigSet, podName string) (string, error) {
-	watch, err := clientset.Core.CoreV1().Pods(tr.Namespace).Watch(context.Background(), metav1.ListOptions{FieldSelector: "metadata.name=" + podName})
-	if err != nil || watch == nil {
-		return "", fmt.Errorf("can't get watch interface, please check taskrun status: %s", err)
-	}
-	defer watch.Stop()
-
-	duration, err := time.ParseDuration(tr.Timeout)
-	if err != nil {
-		duration = 10 * time.Minute
-	}
-
-	ticker := time.NewTicker(duration)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case event := <-watch.ResultChan():
-			if event.Object == nil {
-				return tr.sourceContainer(clientset, podName)
-			}
-			pod, ok := event.Object.(*corev1.Pod)
-			if !ok || pod == nil {
-				continue
-			}
-			for _, v := range pod.Status.ContainerStatuses {
-				if strings.HasSuffix(v.Name, "sources-receiver") {
-					if v.State.Terminated != nil {
-						// Looks like we got watch interface for "previous" service version
-						return "", fmt.Errorf("taskrun container terminated")
-					}
-					if v.State.Running != nil {
-						return v.Name, nil
-					}
+	ctx, cancel := tr.timeout(clientset)
+	defer cancel()
+
+	var container string
+	err := client.Watch(ctx, metav1.ListOptions{
+		FieldSelector: "metadata.name=" + podName,
+	}, func(opts metav1.ListOptions) (watch.Interface, error) {
+		return clientset.Core.CoreV1().Pods(tr.Namespace).Watch(ctx, opts)
+	}, func(event watch.Event) (bool, error) {
+		pod, ok := event.Object.(*corev1.Pod)
+		if !ok || pod == nil {
+			return false, nil
+		}
+		for _, v := range pod.Status.ContainerStatuses {
+			if strings.HasSuffix(v.Name, "sources-receiver") {
+				if v.State.Terminated != nil {
+					// Looks like we got watch interface for "previous" service version
+					return false, fmt.Errorf("taskrun container terminated")
+				}
+				if v.State.Running != nil {
+					container = v.Name
+					return true, nil
 				}
 			}
-		case <-ticker.C:
-			return "", fmt.Errorf("watch pod timeout")
 		}
+		return false, nil
+	})
+	switch {
+	case err == nil:
+		return container, nil
+	case clientset.Context.Err() != nil:
+		return "", clientset.Context.Err()
+	case ctx.Err() != nil:
+		return "", fmt.Errorf("watch pod timeout")
+	}
+	return "", err
+}
+
+// checkSources verifies that local sources fit into the upload size limit.
+// Single file function uploads the whole directory of the file
+func (tr *TaskRun) checkSources(clientset *client.ConfigSet) error {
+	if !file.IsLocal(tr.Function.Path) || clientset.Upload == nil {
+		return nil
+	}
+	source := path.Clean(tr.Function.Path)
+	if !file.IsDir(source) {
+		source = path.Dir(source)
 	}
+	return file.CheckSize(source, clientset.Upload.SizeLimit)
 }
 
 func (tr *TaskRun) injectSources(clientset *client.ConfigSet, pod, container string) error {
@@ -465,6 +623,15 @@ func (tr *TaskRun) injectSources(clientset *client.ConfigSet, pod, container str
 		Source:      tr.Function.Path,
 		Destination: path.Join("/home", path.Base(tr.Function.Path)),
 	}
+	if clientset.Upload != nil {
+		c.Compression = clientset.Upload.Compression
+		c.SizeLimit = clientset.Upload.SizeLimit
+		c.Retries = clientset.Upload.Retries
+	}
+	// upload progress bar would break the multiple deployments view
+	if tr.OnPhase == nil && terminal.IsTerminal(int(os.Stderr.Fd())) && !clientset.Log.IsDebug() {
+		c.Progress = os.Stderr
+	}
 	if err := c.Upload(clientset); err != nil {
 		return err
 	}
@@ -475,37 +642,8 @@ func (tr *TaskRun) injectSources(clientset *client.ConfigSet, pod, container str
 	return nil
 }
 
-func (tr *TaskRun) getBuildArguments(image string) []v1beta1.Param {
-	params := []v1beta1.Param{
-		{
-			Name: "IMAGE",
-			Value: v1beta1.ArrayOrString{
-				Type:      v1beta1.ParamTypeString,
-				StringVal: image,
-			},
-		},
-	}
-	for k, v := range mapFromSlice(tr.Params) {
-		params = append(params, v1beta1.Param{
-			Name: k,
-			Value: v1beta1.ArrayOrString{
-				Type:      v1beta1.ParamTypeString,
-				StringVal: v,
-			},
-		})
-	}
-	return params
-}
-
-func mapFromSlice(slice []string) map[string]string {
-	m := make(map[string]string)
-	for _, s := range slice {
-		t := regexp.MustCompile("[:=]").Split(s, 2)
-		if len(t) != 2 {
-			fmt.Printf("Can't parse argument slice %s\n", s)
-			continue
-		}
-		m[t[0]] = t[1]
+func (tr *TaskRun) phase(phase string) {
+	if tr.OnPhase != nil {
+		tr.OnPhase(phase)
 	}
-	return m
 }
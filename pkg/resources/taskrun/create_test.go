@@ -15,11 +15,25 @@
 package taskrun
 
 import (
+	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	tektonFake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
 	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	kubernetesFake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
 )
 
 func TestTaskRunDryDeployment(t *testing.T) {
@@ -90,3 +104,337 @@ func TestTaskRunDryDeployment(t *testing.T) {
 		}
 	}
 }
+
+func TestSourcesWorkspace(t *testing.T) {
+	cases := []struct {
+		size      string
+		emptyDir  bool
+		claimSize string
+		wantErr   bool
+	}{
+		{size: "", emptyDir: true},
+		{size: "1Gi", claimSize: "1Gi"},
+		{size: "not-a-size", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		tr := TaskRun{WorkspaceSize: tc.size}
+		binding, err := tr.sourcesWorkspace()
+		if tc.wantErr {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, "sources", binding.Name)
+		assert.Equal(t, tc.emptyDir, binding.EmptyDir != nil)
+		if tc.claimSize != "" {
+			storage := binding.VolumeClaimTemplate.Spec.Resources.Requests.Storage()
+			assert.Equal(t, tc.claimSize, storage.String())
+		}
+	}
+}
+
+func TestBuildParams(t *testing.T) {
+	specs := []v1beta1.ParamSpec{
+		{Name: "IMAGE", Type: v1beta1.ParamTypeString},
+		{Name: "DIRECTORY", Type: v1beta1.ParamTypeString, Default: v1beta1.NewArrayOrString(".")},
+		{Name: "EXTRA_ARGS", Type: v1beta1.ParamTypeArray, Default: v1beta1.NewArrayOrString("")},
+		{Name: "REQUIRED", Type: v1beta1.ParamTypeString},
+	}
+	implicit := map[string]string{"IMAGE": "registry/foo:bar", "HANDLER": "main.go"}
+
+	cases := []struct {
+		name     string
+		args     []string
+		specs    []v1beta1.ParamSpec
+		validate bool
+		params   map[string]v1beta1.ArrayOrString
+		errMsgs  []string
+	}{
+		{
+			name:     "valid arguments",
+			args:     []string{"REQUIRED=foo", "EXTRA_ARGS=--a", "EXTRA_ARGS=--b"},
+			specs:    specs,
+			validate: true,
+			params: map[string]v1beta1.ArrayOrString{
+				"IMAGE":      *v1beta1.NewArrayOrString("registry/foo:bar"),
+				"REQUIRED":   *v1beta1.NewArrayOrString("foo"),
+				"EXTRA_ARGS": *v1beta1.NewArrayOrString("--a", "--b"),
+			},
+		},
+		{
+			name:  "no task specs",
+			args:  []string{"FOO:bar", "LIST=a", "LIST=b"},
+			specs: nil,
+			params: map[string]v1beta1.ArrayOrString{
+				"IMAGE":   *v1beta1.NewArrayOrString("registry/foo:bar"),
+				"HANDLER": *v1beta1.NewArrayOrString("main.go"),
+				"FOO":     *v1beta1.NewArrayOrString("bar"),
+				"LIST":    *v1beta1.NewArrayOrString("a", "b"),
+			},
+		},
+		{
+			name:     "all mismatches",
+			args:     []string{"UNKNOWN=foo", "DIRECTORY=a", "DIRECTORY=b", "malformed"},
+			specs:    specs,
+			validate: true,
+			errMsgs: []string{
+				`unknown parameter "UNKNOWN"`,
+				`parameter "DIRECTORY" is a string, got 2 values`,
+				`can't parse build argument "malformed"`,
+				`missing required parameter "REQUIRED"`,
+			},
+		},
+		{
+			name:     "task without params",
+			args:     []string{"FOO=bar"},
+			specs:    nil,
+			validate: true,
+			errMsgs: []string{
+				`unknown parameter "FOO"`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := TaskRun{Params: tc.args, Task: Resource{Name: "foo"}}
+			params, err := tr.buildParams(tc.specs, tc.validate, implicit)
+			if len(tc.errMsgs) != 0 {
+				assert.Error(t, err)
+				for _, msg := range tc.errMsgs {
+					assert.Contains(t, err.Error(), msg)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			result := make(map[string]v1beta1.ArrayOrString)
+			for _, p := range params {
+				result[p.Name] = p.Value
+			}
+			assert.Equal(t, tc.params, result)
+		})
+	}
+}
+
+func TestRegistryMatch(t *testing.T) {
+	auths := registry{
+		"https://index.docker.io/v1/": {Auth: "Zm9vOmJhcg=="},
+		"gcr.io":                      {Username: "oauth2accesstoken"},
+	}
+	testCases := []struct {
+		name     string
+		auths    registry
+		host     string
+		expected string
+		username string
+		err      bool
+	}{
+		{"single registry", registry{"quay.io": {Username: "foo"}}, "knative.registry.svc.cluster.local", "quay.io", "foo", false},
+		{"docker hub", auths, "docker.io", "docker.io", "foo", false},
+		{"gcr", auths, "gcr.io", "gcr.io", "oauth2accesstoken", false},
+		{"no match", auths, "quay.io", "", "", true},
+		{"empty", registry{}, "quay.io", "", "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			host, creds, err := tc.auths.match(tc.host)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, host)
+			assert.Equal(t, tc.username, creds.username())
+		})
+	}
+}
+
+func TestWaitRewatch(t *testing.T) {
+	clientset := fake.NewClient()
+	done := &v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "test-namespace"}}
+	done.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue})
+
+	var watches int
+	clientset.TektonTasks.(*tektonFake.Clientset).PrependWatchReactor("taskruns", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watches++
+		w := watch.NewFakeWithChanSize(1, false)
+		if watches == 1 {
+			// API server closed the watch
+			w.Stop()
+		} else {
+			w.Modify(done)
+		}
+		return true, w, nil
+	})
+
+	tr := &TaskRun{Name: "build", Namespace: "test-namespace"}
+	result, err := tr.wait(&clientset)
+	assert.NoError(t, err)
+	assert.Equal(t, "build", result.Name)
+	assert.Equal(t, 2, watches)
+}
+
+func TestWaitCancel(t *testing.T) {
+	clientset := fake.NewClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	clientset.Context = ctx
+	cancel()
+
+	tr := &TaskRun{Name: "build", Namespace: "test-namespace"}
+	_, err := tr.wait(&clientset)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestTaskPodRewatch(t *testing.T) {
+	client.RewatchDelay = time.Millisecond
+	defer func() { client.RewatchDelay = time.Second }()
+
+	clientset := fake.NewClient()
+	started := &v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "test-namespace"}}
+	started.Status.PodName = "build-pod"
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "build-pod", Namespace: "test-namespace"}}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "step-sources-receiver",
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}}
+
+	// API server closes every first watch
+	var watches int
+	reactor := func(object runtime.Object) k8stesting.WatchReactionFunc {
+		return func(action k8stesting.Action) (bool, watch.Interface, error) {
+			watches++
+			w := watch.NewFakeWithChanSize(1, false)
+			if watches%2 == 1 {
+				w.Stop()
+			} else {
+				w.Modify(object)
+			}
+			return true, w, nil
+		}
+	}
+	clientset.TektonTasks.(*tektonFake.Clientset).PrependWatchReactor("taskruns", reactor(started))
+	clientset.Core.(*kubernetesFake.Clientset).PrependWatchReactor("pods", reactor(pod))
+
+	tr := &TaskRun{Name: "build", Namespace: "test-namespace"}
+	name, err := tr.taskPod(&clientset)
+	assert.NoError(t, err)
+	assert.Equal(t, "build-pod", name)
+
+	container, err := tr.sourceContainer(&clientset, name)
+	assert.NoError(t, err)
+	assert.Equal(t, "step-sources-receiver", container)
+	assert.Equal(t, 4, watches)
+}
+
+// buildReactors makes fake builds fail until the attempt number succeeding, all builds
+// fail if it is 0. Returned functions report failed, succeeded and cancelled TaskRuns
+func buildReactors(clientset client.ConfigSet, succeeding int) (failed, succeeded, cancelled func() []string) {
+	var attempts int
+	var last *v1beta1.TaskRun
+	var cancels []string
+	tekton := clientset.TektonTasks.(*tektonFake.Clientset)
+	tekton.PrependReactor("create", "taskruns", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		attempts++
+		last = action.(k8stesting.CreateAction).GetObject().(*v1beta1.TaskRun)
+		status := corev1.ConditionFalse
+		if attempts == succeeding {
+			status = corev1.ConditionTrue
+		}
+		last.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: status, Message: "build failed"})
+		return false, nil, nil
+	})
+	tekton.PrependWatchReactor("taskruns", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Modify(last)
+		return true, w, nil
+	})
+	tekton.PrependReactor("patch", "taskruns", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		cancels = append(cancels, action.(k8stesting.PatchAction).GetName())
+		return true, &v1beta1.TaskRun{}, nil
+	})
+	names := func(succeeded bool) []string {
+		list, err := tekton.TektonV1beta1().TaskRuns("test-namespace").List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return nil
+		}
+		var result []string
+		for _, taskrun := range list.Items {
+			if taskrun.IsSuccessful() == succeeded {
+				result = append(result, taskrun.Name)
+			}
+		}
+		return result
+	}
+	return func() []string { return names(false) },
+		func() []string { return names(true) },
+		func() []string { return cancels }
+}
+
+func TestDeployRetry(t *testing.T) {
+	client.Dry = false
+	buildRetryDelay = time.Millisecond
+	defer func() { buildRetryDelay = 5 * time.Second }()
+
+	clientset := fake.NewClient(&v1beta1.Task{ObjectMeta: metav1.ObjectMeta{Name: "kaniko", Namespace: "test-namespace"}})
+	failed, succeeded, cancelled := buildReactors(clientset, 2)
+
+	tr := &TaskRun{Name: "foo", Namespace: "test-namespace", Task: Resource{Name: "kaniko"}, Wait: true, Retries: 2}
+	image, err := tr.Deploy(&clientset)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, image)
+	assert.Len(t, failed(), 1)
+	assert.Len(t, succeeded(), 1)
+	// failed attempt is cancelled, successful one is not
+	assert.Equal(t, failed(), cancelled())
+}
+
+func TestDeployNoRetry(t *testing.T) {
+	client.Dry = false
+	buildRetryDelay = time.Millisecond
+	defer func() { buildRetryDelay = 5 * time.Second }()
+
+	clientset := fake.NewClient(&v1beta1.Task{ObjectMeta: metav1.ObjectMeta{Name: "kaniko", Namespace: "test-namespace"}})
+	var creates int
+	clientset.TektonTasks.(*tektonFake.Clientset).PrependReactor("create", "taskruns", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		creates++
+		return true, nil, errors.New("admission webhook denied the request")
+	})
+
+	tr := &TaskRun{Name: "foo", Namespace: "test-namespace", Task: Resource{Name: "kaniko"}, Wait: true, Retries: 2}
+	_, err := tr.Deploy(&clientset)
+	assert.EqualError(t, err, "creating taskrun: admission webhook denied the request")
+	assert.Equal(t, 1, creates, "build that was not created must not be retried")
+}
+
+func TestRunCancelOnError(t *testing.T) {
+	client.Dry = false
+	buildRetryDelay = time.Millisecond
+	defer func() { buildRetryDelay = 5 * time.Second }()
+
+	clientset := fake.NewClient(&v1beta1.Task{ObjectMeta: metav1.ObjectMeta{Name: "kaniko", Namespace: "test-namespace"}})
+	failed, _, cancelled := buildReactors(clientset, 0)
+
+	tr := &TaskRun{Name: "foo", Namespace: "test-namespace", Task: Resource{Name: "kaniko"}, Wait: true, Retries: 1}
+	_, err := tr.Deploy(&clientset)
+	assert.Error(t, err)
+	assert.Len(t, failed(), 2)
+	assert.ElementsMatch(t, failed(), cancelled(), "every failed attempt must be cancelled")
+}
+
+func TestDeploySizeLimit(t *testing.T) {
+	client.Dry = false
+	dir, err := os.MkdirTemp("", "tm-sources-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), make([]byte, 2048), 0644))
+
+	clientset := fake.NewClient(&v1beta1.Task{ObjectMeta: metav1.ObjectMeta{Name: "kaniko", Namespace: "test-namespace"}})
+	clientset.Upload.SizeLimit = 1024
+
+	tr := &TaskRun{Name: "foo", Namespace: "test-namespace", Task: Resource{Name: "kaniko"}, Function: Source{Path: filepath.Join(dir, "main.go")}}
+	_, err = tr.Deploy(&clientset)
+	assert.EqualError(t, err, "sources size 2.0KiB exceeds upload limit 1.0KiB, exclude unneeded files with .tmignore or increase the limit")
+	// neither cloned task nor taskrun is created
+	assert.Empty(t, clientset.TektonTasks.(*tektonFake.Clientset).Actions())
+}
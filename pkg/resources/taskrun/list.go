@@ -15,7 +15,6 @@
 package taskrun
 
 import (
-	"context"
 	"fmt"
 	"time"
 
@@ -24,6 +23,7 @@ import (
 	"github.com/triggermesh/tm/pkg/printer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/watch"
 	"knative.dev/pkg/apis"
 )
 
@@ -70,5 +70,20 @@ func (tr *TaskRun) row(item *v1beta1.TaskRun) []string {
 
 // List returns k8s list object
 func (tr *TaskRun) List(clientset *client.ConfigSet) (*v1beta1.TaskRunList, error) {
-	return clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).List(context.Background(), metav1.ListOptions{})
+	list := &v1beta1.TaskRunList{}
+	err := client.ListPages(client.ListOptions(), func(opts metav1.ListOptions) (string, error) {
+		page, err := clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).List(clientset.Context, opts)
+		if err != nil {
+			return "", err
+		}
+		list.Items = append(list.Items, page.Items...)
+		list.ResourceVersion = page.ResourceVersion
+		return page.Continue, nil
+	})
+	return list, err
+}
+
+// Watch opens watch of taskruns changes
+func (tr *TaskRun) Watch(clientset *client.ConfigSet, opts metav1.ListOptions) (watch.Interface, error) {
+	return clientset.TektonTasks.TektonV1beta1().TaskRuns(tr.Namespace).Watch(clientset.Context, opts)
 }
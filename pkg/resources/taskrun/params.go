@@ -0,0 +1,154 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskrun
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/triggermesh/tm/pkg/client"
+	"github.com/triggermesh/tm/pkg/resources/clustertask"
+	"github.com/triggermesh/tm/pkg/resources/task"
+)
+
+// Params set by tm itself, they are passed to the task only if it declares them
+const (
+	imageParam   = "IMAGE"
+	handlerParam = "HANDLER"
+)
+
+var buildArgRegexp = regexp.MustCompile("[:=]")
+
+// taskParams returns params declared in the TaskRun's task or cluster task
+func (tr *TaskRun) taskParams(clientset *client.ConfigSet) ([]v1beta1.ParamSpec, error) {
+	if tr.Task.ClusterScope {
+		ct := clustertask.ClusterTask{Name: tr.Task.Name}
+		clusterTask, err := ct.Get(clientset)
+		if err != nil {
+			return nil, err
+		}
+		return clusterTask.Spec.Params, nil
+	}
+	t := task.Task{Name: tr.Task.Name, Namespace: tr.Namespace}
+	taskObj, err := t.Get(clientset)
+	if err != nil {
+		return nil, err
+	}
+	return taskObj.Spec.Params, nil
+}
+
+// buildParams converts "NAME=VALUE" build arguments into TaskRun params.
+// Repeated arguments compose array params. If validate is set, arguments are validated
+// against task params specs, which may be empty, and all mismatches are returned in a single error.
+// Implicit params are overridden by build arguments with the same names.
+func (tr *TaskRun) buildParams(specs []v1beta1.ParamSpec, validate bool, implicit map[string]string) ([]v1beta1.Param, error) {
+	var errs []string
+	var names []string
+	values := make(map[string][]string)
+	for _, arg := range tr.Params {
+		kv := buildArgRegexp.Split(arg, 2)
+		if len(kv) != 2 || kv[0] == "" {
+			errs = append(errs, fmt.Sprintf("can't parse build argument %q, expected NAME=VALUE", arg))
+			continue
+		}
+		if _, exists := values[kv[0]]; !exists {
+			names = append(names, kv[0])
+		}
+		values[kv[0]] = append(values[kv[0]], kv[1])
+	}
+
+	declared := make(map[string]v1beta1.ParamSpec, len(specs))
+	for _, spec := range specs {
+		declared[spec.Name] = spec
+	}
+
+	var implicitNames []string
+	for name := range implicit {
+		implicitNames = append(implicitNames, name)
+	}
+	sort.Strings(implicitNames)
+	for _, name := range implicitNames {
+		if _, exists := values[name]; exists {
+			continue
+		}
+		if _, exists := declared[name]; !exists && validate && name != imageParam {
+			continue
+		}
+		names = append(names, name)
+		values[name] = []string{implicit[name]}
+	}
+
+	var params []v1beta1.Param
+	for _, name := range names {
+		spec, exists := declared[name]
+		if !exists {
+			if validate && name != imageParam {
+				errs = append(errs, fmt.Sprintf("unknown parameter %q", name))
+				continue
+			}
+			// without task specs array type is assumed for repeated arguments
+			spec.Type = v1beta1.ParamTypeString
+			if len(values[name]) > 1 {
+				spec.Type = v1beta1.ParamTypeArray
+			}
+		}
+		value := v1beta1.ArrayOrString{
+			Type: v1beta1.ParamTypeString,
+		}
+		switch spec.Type {
+		case v1beta1.ParamTypeArray:
+			value.Type = v1beta1.ParamTypeArray
+			value.ArrayVal = values[name]
+		case v1beta1.ParamTypeString, "":
+			if len(values[name]) > 1 {
+				errs = append(errs, fmt.Sprintf("parameter %q is a string, got %d values", name, len(values[name])))
+				continue
+			}
+			value.StringVal = values[name][0]
+		default:
+			errs = append(errs, fmt.Sprintf("parameter %q has unsupported type %q", name, spec.Type))
+			continue
+		}
+		params = append(params, v1beta1.Param{
+			Name:  name,
+			Value: value,
+		})
+	}
+
+	for _, spec := range specs {
+		if _, exists := values[spec.Name]; exists || spec.Default != nil {
+			continue
+		}
+		errs = append(errs, fmt.Sprintf("missing required parameter %q", spec.Name))
+	}
+
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("build arguments do not match task %q params: %s", tr.Task.Name, strings.Join(errs, "; "))
+	}
+	return params, nil
+}
+
+// Image returns the image name passed to the TaskRun build
+func Image(taskrun *v1beta1.TaskRun) string {
+	for _, param := range taskrun.Spec.Params {
+		if param.Name == imageParam {
+			return param.Value.StringVal
+		}
+	}
+	return ""
+}
@@ -19,7 +19,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/triggermesh/tm/pkg/client"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/triggermesh/tm/pkg/client/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestList(t *testing.T) {
@@ -27,13 +30,13 @@ func TestList(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewClient(&v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: namespace}})
 
 	taskRun := &TaskRun{Name: "Foo", Namespace: namespace}
 
-	_, err = taskRun.List(&testClient)
+	list, err := taskRun.List(&testClient)
 	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
 }
 
 func TestGet(t *testing.T) {
@@ -41,13 +44,11 @@ func TestGet(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewClient()
 
 	taskRun := &TaskRun{Name: "Foo", Namespace: namespace}
-	result, err := taskRun.Get(&testClient)
-	assert.Error(t, err)
-	assert.Equal(t, "", result.Name)
+	_, err := taskRun.Get(&testClient)
+	assert.True(t, k8serrors.IsNotFound(err))
 }
 
 func TestDelete(t *testing.T) {
@@ -55,10 +56,9 @@ func TestDelete(t *testing.T) {
 	if ns, ok := os.LookupEnv("NAMESPACE"); ok {
 		namespace = ns
 	}
-	testClient, err := client.NewClient(client.ConfigPath(""))
-	assert.NoError(t, err)
+	testClient := fake.NewClient()
 
 	taskRun := &TaskRun{Name: "Foo", Namespace: namespace}
-	err = taskRun.Delete(&testClient)
+	err := taskRun.Delete(&testClient)
 	assert.Error(t, err)
 }
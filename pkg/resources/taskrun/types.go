@@ -14,6 +14,13 @@
 
 package taskrun
 
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/triggermesh/tm/pkg/resources/cache"
+)
+
 // TaskRun represents tekton TaskRun object
 type TaskRun struct {
 	Function         Source
@@ -24,6 +31,19 @@ type TaskRun struct {
 	Task             Resource
 	Timeout          string
 	Wait             bool
+	// Retries is the number of attempts to re-create failed TaskRun
+	Retries int
+	// WorkspaceSize is the size of the volume claim for git sources,
+	// if empty, sources are cloned into the emptyDir volume
+	WorkspaceSize string
+	// Cache is the storage of image build cache
+	Cache cache.Cache
+	// Digest of the built image, set after successful build if it is known
+	Digest string
+	// ServiceAccount of the TaskRun pod, namespace default account if empty
+	ServiceAccount string
+	// OnPhase is called when sources upload starts and when build begins
+	OnPhase func(phase string)
 }
 
 // Resource is a generic structure to describe k8s resource
@@ -48,6 +68,20 @@ type registryAuths struct {
 type credentials struct {
 	Username string
 	Password string
+	// Auth is base64 encoded "username:password" string
+	Auth string
+}
+
+// username returns the registry username, decoding it from the auth string if necessary
+func (c credentials) username() string {
+	if c.Username != "" {
+		return c.Username
+	}
+	decoded, err := base64.StdEncoding.DecodeString(c.Auth)
+	if err != nil {
+		return ""
+	}
+	return strings.SplitN(string(decoded), ":", 2)[0]
 }
 
 type registry map[string]credentials
@@ -0,0 +1,238 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtimes
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/triggermesh/tm/pkg/printer"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+const catalogFile = "catalog.yaml"
+
+var (
+	// CatalogSource is the local path or URL of the runtimes index
+	CatalogSource = DefaultCatalog
+	// CacheDir is the local storage of the installed runtimes and the catalog copy
+	CacheDir = filepath.Join(homeDir(), ".tm", "runtimes")
+)
+
+var referenceRegexp = regexp.MustCompile(`^([a-z0-9][a-z0-9._-]*)(@([A-Za-z0-9._-]+))?$`)
+
+func homeDir() string {
+	if dir := os.Getenv("HOME"); dir != "" {
+		return dir
+	}
+	return "."
+}
+
+// ParseReference parses runtime short name with optional version, e.g. "python37@v1.2".
+// Second return value is false if the string is not a runtime reference.
+func ParseReference(runtime string) (Reference, bool) {
+	match := referenceRegexp.FindStringSubmatch(runtime)
+	if match == nil {
+		return Reference{}, false
+	}
+	return Reference{
+		Name:    match[1],
+		Version: match[3],
+	}, true
+}
+
+// LoadCatalog reads the runtimes index from provided local path or URL.
+// Remote index is saved in the local cache and the cached copy is used
+// when the source is not reachable.
+func LoadCatalog(source string) (*Catalog, error) {
+	if source == "" {
+		source = DefaultCatalog
+	}
+	cached := filepath.Join(CacheDir, catalogFile)
+	data, err := fetch(source)
+	if err != nil {
+		if !isURL(source) {
+			return nil, fmt.Errorf("reading catalog: %s", err)
+		}
+		var cacheErr error
+		if data, cacheErr = ioutil.ReadFile(cached); cacheErr != nil {
+			return nil, fmt.Errorf("fetching catalog: %s", err)
+		}
+	} else if isURL(source) {
+		if err := writeFile(cached, data); err != nil {
+			return nil, fmt.Errorf("caching catalog: %s", err)
+		}
+	}
+
+	var catalog Catalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("parsing catalog: %s", err)
+	}
+	return &catalog, nil
+}
+
+// Names returns sorted list of the catalog runtimes
+func (c *Catalog) Names() []string {
+	var names []string
+	for name := range c.Runtimes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve finds runtime version in the catalog. If version is not set,
+// the latest runtime version is returned.
+func (c *Catalog) Resolve(ref Reference) (Reference, Version, error) {
+	runtime, exists := c.Runtimes[ref.Name]
+	if !exists {
+		return ref, Version{}, fmt.Errorf("runtime %q not found in catalog", ref.Name)
+	}
+	if ref.Version == "" {
+		ref.Version = runtime.LatestVersion()
+	}
+	v, exists := runtime.Versions[ref.Version]
+	if !exists {
+		return ref, Version{}, fmt.Errorf("runtime %q version %q not found, available versions: %s",
+			ref.Name, ref.Version, strings.Join(runtime.SortedVersions(), ", "))
+	}
+	return ref, v, nil
+}
+
+// LatestVersion returns version marked as latest in catalog or the newest one
+func (r Runtime) LatestVersion() string {
+	if r.Latest != "" {
+		return r.Latest
+	}
+	if versions := r.SortedVersions(); len(versions) != 0 {
+		return versions[0]
+	}
+	return ""
+}
+
+// SortedVersions returns runtime versions, newest first
+func (r Runtime) SortedVersions() []string {
+	var versions []string
+	for v := range r.Versions {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		a, errA := version.ParseGeneric(versions[i])
+		b, errB := version.ParseGeneric(versions[j])
+		if errA != nil || errB != nil {
+			return versions[i] > versions[j]
+		}
+		return b.LessThan(a)
+	})
+	return versions
+}
+
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// fetch reads the content of local file or URL
+func fetch(source string) ([]byte, error) {
+	if !isURL(source) {
+		return ioutil.ReadFile(source)
+	}
+	resp, err := http.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", source, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// writeFile atomically replaces file content so that parallel builds
+// never read partially written cache
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// GetTable converts catalog into the table with runtimes and their versions
+func (c *Catalog) GetTable() printer.Table {
+	table := printer.Table{
+		Headers: []string{"Name", "Latest", "Versions", "Installed", "Description"},
+		Rows:    make([][]string, 0, len(c.Runtimes)),
+	}
+	for _, name := range c.Names() {
+		runtime := c.Runtimes[name]
+		var installed []string
+		versions := runtime.SortedVersions()
+		for _, v := range versions {
+			if _, ok := Cached(Reference{Name: name, Version: v}); ok {
+				installed = append(installed, v)
+			}
+		}
+		table.Rows = append(table.Rows, []string{
+			name,
+			runtime.LatestVersion(),
+			strings.Join(versions, ","),
+			strings.Join(installed, ","),
+			runtime.Description,
+		})
+	}
+	return table
+}
+
+// GetVersionsTable returns the table with runtime versions details
+func (c *Catalog) GetVersionsTable(name string) (printer.Table, error) {
+	table := printer.Table{
+		Headers: []string{"Version", "URL", "SHA256", "Installed"},
+	}
+	runtime, exists := c.Runtimes[name]
+	if !exists {
+		return table, fmt.Errorf("runtime %q not found in catalog", name)
+	}
+	for _, v := range runtime.SortedVersions() {
+		path, _ := Cached(Reference{Name: name, Version: v})
+		table.Rows = append(table.Rows, []string{
+			v,
+			runtime.Versions[v].URL,
+			runtime.Versions[v].SHA256,
+			path,
+		})
+	}
+	return table, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtimes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	manifestFile   = "runtime.yaml"
+	checksumSuffix = ".sha256"
+)
+
+// Path returns the location of the runtime task manifest in the local cache
+func Path(ref Reference) string {
+	return filepath.Join(CacheDir, ref.Name, ref.Version, manifestFile)
+}
+
+// Cached returns the path to the installed runtime manifest.
+// Second return value is false if runtime is not installed or its checksum does not match.
+func Cached(ref Reference) (string, bool) {
+	if ref.Version == "" {
+		return "", false
+	}
+	path := Path(ref)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	sum, err := ioutil.ReadFile(path + checksumSuffix)
+	if err != nil {
+		return "", false
+	}
+	if checksum(data) != strings.TrimSpace(string(sum)) {
+		return "", false
+	}
+	return path, true
+}
+
+// Install downloads the runtime version into the local cache and verifies its checksum.
+// Already installed runtime is not downloaded again.
+func Install(ref Reference, v Version) (string, error) {
+	if path, ok := Cached(ref); ok && (v.SHA256 == "" || strings.EqualFold(v.SHA256, installedChecksum(path))) {
+		return path, nil
+	}
+	data, err := fetch(v.URL)
+	if err != nil {
+		return "", fmt.Errorf("downloading runtime %s: %s", ref, err)
+	}
+	sum := checksum(data)
+	if v.SHA256 != "" && !strings.EqualFold(v.SHA256, sum) {
+		return "", fmt.Errorf("runtime %s checksum mismatch: expected %s, got %s", ref, v.SHA256, sum)
+	}
+	path := Path(ref)
+	if err := writeFile(path, data); err != nil {
+		return "", err
+	}
+	if err := writeFile(path+checksumSuffix, []byte(sum+"\n")); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// Resolve returns the local path to the task manifest of the runtime reference, e.g. "python37@v1.2".
+// Installed versions are served from the cache without the catalog lookup,
+// other runtimes are resolved in the catalog and installed.
+func Resolve(runtime string) (string, error) {
+	ref, ok := ParseReference(runtime)
+	if !ok {
+		return "", fmt.Errorf("%q is not a runtime reference", runtime)
+	}
+	if path, ok := Cached(ref); ok {
+		return path, nil
+	}
+	catalog, err := LoadCatalog(CatalogSource)
+	if err != nil {
+		return "", err
+	}
+	ref, v, err := catalog.Resolve(ref)
+	if err != nil {
+		return "", err
+	}
+	return Install(ref, v)
+}
+
+func installedChecksum(path string) string {
+	sum, err := ioutil.ReadFile(path + checksumSuffix)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(sum))
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
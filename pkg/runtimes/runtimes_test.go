@@ -0,0 +1,134 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtimes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const runtimeManifest = "apiVersion: tekton.dev/v1beta1\nkind: Task\n"
+
+func TestParseReference(t *testing.T) {
+	testCases := []struct {
+		runtime string
+		ref     Reference
+		ok      bool
+	}{
+		{"python37", Reference{Name: "python37"}, true},
+		{"python37@v1.2", Reference{Name: "python37", Version: "v1.2"}, true},
+		{"node-10@1.0.0", Reference{Name: "node-10", Version: "1.0.0"}, true},
+		{"https://example.com/runtime.yaml", Reference{}, false},
+		{"../runtime.yaml", Reference{}, false},
+		{"python37@", Reference{}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.runtime, func(t *testing.T) {
+			ref, ok := ParseReference(tc.runtime)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.ref, ref)
+		})
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	sum := sha256.Sum256([]byte(runtimeManifest))
+	var online = true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !online {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/catalog.yaml":
+			fmt.Fprintf(w, `runtimes:
+  python37:
+    description: Python 3.7
+    versions:
+      v1.2:
+        url: %[1]s/v1.2/runtime.yaml
+        sha256: %[2]s
+      v1.10:
+        url: %[1]s/v1.10/runtime.yaml
+        sha256: %[2]s
+  go:
+    latest: v1.0
+    versions:
+      v1.0:
+        url: %[1]s/go/runtime.yaml
+        sha256: deadbeef
+      v2.0:
+        url: %[1]s/go/runtime.yaml
+`, "http://"+r.Host, hex.EncodeToString(sum[:]))
+		default:
+			fmt.Fprint(w, runtimeManifest)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "tm-runtimes")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	CacheDir = dir
+	CatalogSource = server.URL + "/catalog.yaml"
+
+	catalog, err := LoadCatalog(CatalogSource)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "python37"}, catalog.Names())
+
+	ref, _, err := catalog.Resolve(Reference{Name: "python37"})
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.10", ref.Version)
+	ref, _, err = catalog.Resolve(Reference{Name: "go"})
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.0", ref.Version)
+	_, _, err = catalog.Resolve(Reference{Name: "python37", Version: "v0.1"})
+	assert.Error(t, err)
+	_, _, err = catalog.Resolve(Reference{Name: "java"})
+	assert.Error(t, err)
+
+	_, err = Resolve("go@v1.0")
+	assert.Error(t, err, "checksum mismatch expected")
+
+	path, err := Resolve("python37@v1.2")
+	require.NoError(t, err)
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, runtimeManifest, string(data))
+
+	// cached catalog and runtimes are used when the source is not available
+	online = false
+	cachedPath, err := Resolve("python37@v1.2")
+	assert.NoError(t, err)
+	assert.Equal(t, path, cachedPath)
+	_, err = LoadCatalog(CatalogSource)
+	assert.NoError(t, err)
+
+	// modified cache is not trusted
+	require.NoError(t, ioutil.WriteFile(path, []byte("foo"), 0644))
+	_, ok := Cached(Reference{Name: "python37", Version: "v1.2"})
+	assert.False(t, ok)
+	_, err = Resolve("python37@v1.2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtimes
+
+// DefaultCatalog is the index of knative-lambda-runtime tasks
+const DefaultCatalog = "https://raw.githubusercontent.com/triggermesh/knative-lambda-runtime/master/catalog.yaml"
+
+// Catalog is an index of available runtimes with their versions.
+// Index file has the following format:
+//
+//	runtimes:
+//	  python37:
+//	    description: Python 3.7 runtime
+//	    latest: v1.2
+//	    versions:
+//	      v1.2:
+//	        url: https://raw.githubusercontent.com/triggermesh/knative-lambda-runtime/v1.2/python37/runtime.yaml
+//	        sha256: 5f1d...
+type Catalog struct {
+	Runtimes map[string]Runtime `yaml:"runtimes"`
+}
+
+// Runtime is a single catalog entry
+type Runtime struct {
+	Description string             `yaml:"description,omitempty"`
+	Latest      string             `yaml:"latest,omitempty"`
+	Versions    map[string]Version `yaml:"versions"`
+}
+
+// Version contains the location of runtime task manifest and its checksum
+type Version struct {
+	URL    string `yaml:"url"`
+	SHA256 string `yaml:"sha256,omitempty"`
+}
+
+// Reference is a parsed "name@version" runtime reference
+type Reference struct {
+	Name    string
+	Version string
+}
+
+func (r Reference) String() string {
+	if r.Version == "" {
+		return r.Name
+	}
+	return r.Name + "@" + r.Version
+}
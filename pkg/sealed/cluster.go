@@ -0,0 +1,77 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sealed
+
+import (
+	"fmt"
+
+	"github.com/triggermesh/tm/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// KeySecret is the name of the cluster Secret with the key pair
+	KeySecret = "tm-sealed-key"
+
+	privateKeyField = "private.key"
+	publicKeyField  = "public.key"
+)
+
+// ClusterKey returns the key pair stored in the namespace Secret.
+// If create is set and Secret does not exist, new key pair is generated and stored.
+func ClusterKey(clientset *client.ConfigSet, namespace string, create bool) (KeyPair, error) {
+	ctx := clientset.Context
+	secret, err := clientset.Core.CoreV1().Secrets(namespace).Get(ctx, KeySecret, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) && create {
+		return createClusterKey(clientset, namespace)
+	}
+	if err != nil {
+		return KeyPair{}, fmt.Errorf("reading key secret %q: %s", KeySecret, err)
+	}
+	if private, ok := secret.Data[privateKeyField]; ok {
+		return ParsePrivateKey(string(private))
+	}
+	return ParsePublicKey(string(secret.Data[publicKeyField]))
+}
+
+func createClusterKey(clientset *client.ConfigSet, namespace string) (KeyPair, error) {
+	key, err := GenerateKey()
+	if err != nil {
+		return key, err
+	}
+	secret := corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      KeySecret,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		StringData: map[string]string{
+			privateKeyField: key.PrivateKey(),
+			publicKeyField:  key.PublicKey(),
+		},
+	}
+	_, err = clientset.Core.CoreV1().Secrets(namespace).Create(clientset.Context, &secret, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		// key was created concurrently, use it instead of the generated one
+		return ClusterKey(clientset, namespace, false)
+	}
+	return key, err
+}
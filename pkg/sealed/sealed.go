@@ -0,0 +1,184 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sealed encrypts values that are stored in the repository together
+// with the manifest. Values are encrypted with age (https://age-encryption.org)
+// X25519 recipient and can be opened only with the identity kept in the cluster
+// Secret or in the local key file, which has the age-keygen format.
+package sealed
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const (
+	prefix = "ENC[age,"
+	suffix = "]"
+)
+
+// KeyPair is the key pair used to seal and open values.
+// Identity may be empty if key pair is used only for encryption.
+type KeyPair struct {
+	identity  *age.X25519Identity
+	recipient *age.X25519Recipient
+}
+
+// GenerateKey returns new random key pair
+func GenerateKey() (KeyPair, error) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return KeyPair{}, err
+	}
+	return KeyPair{identity: identity, recipient: identity.Recipient()}, nil
+}
+
+// ParsePrivateKey decodes "AGE-SECRET-KEY-1..." private key and derives its public key
+func ParsePrivateKey(encoded string) (KeyPair, error) {
+	identity, err := age.ParseX25519Identity(strings.TrimSpace(encoded))
+	if err != nil {
+		return KeyPair{}, fmt.Errorf("private key: %s", err)
+	}
+	return KeyPair{identity: identity, recipient: identity.Recipient()}, nil
+}
+
+// ParsePublicKey decodes "age1..." public key
+func ParsePublicKey(encoded string) (KeyPair, error) {
+	recipient, err := age.ParseX25519Recipient(strings.TrimSpace(encoded))
+	if err != nil {
+		return KeyPair{}, fmt.Errorf("public key: %s", err)
+	}
+	return KeyPair{recipient: recipient}, nil
+}
+
+// PublicKey returns age recipient string
+func (k KeyPair) PublicKey() string {
+	return k.recipient.String()
+}
+
+// PrivateKey returns age identity string
+func (k KeyPair) PrivateKey() string {
+	if k.identity == nil {
+		return ""
+	}
+	return k.identity.String()
+}
+
+// Encrypt seals the value with the public key and returns it in "ENC[age,...]" format,
+// where the base64 encoded payload is the binary age file
+func (k KeyPair) Encrypt(value []byte) (string, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, k.recipient)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(value); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return prefix + base64.StdEncoding.EncodeToString(buf.Bytes()) + suffix, nil
+}
+
+// Decrypt opens the value encrypted with Encrypt or ASCII armored value
+// encrypted with "age --armor"
+func (k KeyPair) Decrypt(value string) ([]byte, error) {
+	if k.identity == nil {
+		return nil, fmt.Errorf("private key is not set")
+	}
+	value = strings.TrimSpace(value)
+	var src io.Reader
+	switch {
+	case strings.HasPrefix(value, armor.Header):
+		src = armor.NewReader(strings.NewReader(value))
+	case IsEncrypted(value):
+		sealed, err := base64.StdEncoding.DecodeString(value[len(prefix) : len(value)-len(suffix)])
+		if err != nil {
+			return nil, fmt.Errorf("decoding value: %s", err)
+		}
+		src = bytes.NewReader(sealed)
+	default:
+		return nil, fmt.Errorf("value is not encrypted")
+	}
+	r, err := age.Decrypt(src, k.identity)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, fmt.Errorf("value cannot be decrypted with the key")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decrypting value: %s", err)
+	}
+	opened, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting value: %s", err)
+	}
+	return opened, nil
+}
+
+// IsEncrypted returns true if value has encrypted value format
+func IsEncrypted(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, prefix) && strings.HasSuffix(value, suffix) ||
+		strings.HasPrefix(value, armor.Header)
+}
+
+// LoadKeyFile reads private key from the local age-keygen file
+func LoadKeyFile(path string) (KeyPair, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	defer f.Close()
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return KeyPair{}, fmt.Errorf("%s: %s", path, err)
+	}
+	for _, i := range identities {
+		if identity, ok := i.(*age.X25519Identity); ok {
+			return KeyPair{identity: identity, recipient: identity.Recipient()}, nil
+		}
+	}
+	return KeyPair{}, fmt.Errorf("%s: no X25519 identity", path)
+}
+
+// WriteKeyFile saves private key into the local file readable only by the owner.
+// File has the age-keygen format and can be passed to "age --decrypt -i".
+// Existing file is not overwritten.
+func WriteKeyFile(path string, key KeyPair) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(f, "# created: %s\n# public key: %s\n%s\n",
+		time.Now().Format(time.RFC3339), key.PublicKey(), key.PrivateKey()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
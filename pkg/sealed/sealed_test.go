@@ -0,0 +1,129 @@
+// Copyright 2020 TriggerMesh Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sealed
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	encrypted, err := key.Encrypt([]byte("s3cr3t"))
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "s3cr3t")
+
+	decrypted, err := key.Decrypt(encrypted + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", string(decrypted))
+
+	// public key is enough to encrypt
+	public, err := ParsePublicKey(key.PublicKey())
+	require.NoError(t, err)
+	encrypted, err = public.Encrypt([]byte("foo"))
+	require.NoError(t, err)
+	_, err = public.Decrypt(encrypted)
+	assert.EqualError(t, err, "private key is not set")
+
+	// private key restores the same key pair
+	restored, err := ParsePrivateKey(key.PrivateKey())
+	require.NoError(t, err)
+	assert.Equal(t, key.PublicKey(), restored.PublicKey())
+	decrypted, err = restored.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(decrypted))
+
+	other, err := GenerateKey()
+	require.NoError(t, err)
+	_, err = other.Decrypt(encrypted)
+	assert.EqualError(t, err, "value cannot be decrypted with the key")
+}
+
+func TestDecryptArmored(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	// same as "age --armor --recipient <public key>"
+	var buf bytes.Buffer
+	a := armor.NewWriter(&buf)
+	recipient, err := age.ParseX25519Recipient(key.PublicKey())
+	require.NoError(t, err)
+	w, err := age.Encrypt(a, recipient)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("s3cr3t"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, a.Close())
+
+	assert.True(t, IsEncrypted(buf.String()))
+	decrypted, err := key.Decrypt(buf.String())
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", string(decrypted))
+}
+
+func TestIsEncrypted(t *testing.T) {
+	testCases := map[string]bool{
+		"ENC[age,Zm9v]":    true,
+		" ENC[age,Zm9v]\n": true,
+		"ENC[age,Zm9v":     false,
+		"ENC[tm,Zm9v]":     false,
+		armor.Header:       true,
+		"plain value":      false,
+		"":                 false,
+	}
+	for value, expected := range testCases {
+		assert.Equal(t, expected, IsEncrypted(value), value)
+	}
+}
+
+func TestKeyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tm-sealed")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys", "secret.key")
+
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, WriteKeyFile(path, key))
+	assert.Error(t, WriteKeyFile(path, key), "existing key must not be overwritten")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := LoadKeyFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, key.PrivateKey(), loaded.PrivateKey())
+	assert.Equal(t, key.PublicKey(), loaded.PublicKey())
+
+	// key file is readable by age
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	identities, err := age.ParseIdentities(f)
+	require.NoError(t, err)
+	assert.Len(t, identities, 1)
+}